@@ -0,0 +1,166 @@
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaskArgs is the parsed argument of a mask tag.
+//
+// Arguments in parentheses are parsed as a comma-separated list of positional values and named values.
+// For example, `mask:"partial(2,last=4)"` has the positional value "2" and the named value last="4".
+// Values can be quoted with single quotes to contain commas, parentheses or equal signs,
+// and two single quotes in a row escape a quote.
+//
+// Any other argument is the legacy form, and it is split by commas into positional values.
+// For example, `mask:"partial2,4"` has the positional values "2" and "4".
+type MaskArgs struct {
+	raw        string
+	positional []string
+	named      map[string]string
+	// single is true if raw is the only positional value, which is kept without a slice to save an allocation.
+	single bool
+}
+
+// ParseMaskArgs parses the argument passed to a mask function.
+func ParseMaskArgs(arg string) (MaskArgs, error) {
+	args := MaskArgs{raw: arg}
+	if !isStructuredArg(arg) {
+		switch {
+		case strings.IndexByte(arg, ',') >= 0:
+			args.positional = strings.Split(arg, ",")
+		case arg != "":
+			args.single = true
+		}
+		return args, nil
+	}
+
+	inner := arg[1 : len(arg)-1]
+	if strings.TrimSpace(inner) == "" {
+		return args, nil
+	}
+
+	var (
+		item    strings.Builder
+		name    string
+		named   bool
+		quoted  bool
+		wasQuot bool // the current value was quoted
+	)
+	flush := func() error {
+		value := item.String()
+		if !wasQuot {
+			value = strings.TrimSpace(value)
+		}
+		item.Reset()
+		defer func() { name, named, wasQuot = "", false, false }()
+		if !named {
+			if len(args.named) > 0 {
+				return errors.New("positional value after named value")
+			}
+			args.positional = append(args.positional, value)
+			return nil
+		}
+		if name == "" {
+			return errors.New("empty name")
+		}
+		if args.named == nil {
+			args.named = make(map[string]string)
+		}
+		if _, ok := args.named[name]; ok {
+			return fmt.Errorf("duplicate name %q", name)
+		}
+		args.named[name] = value
+		return nil
+	}
+
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case quoted && c == '\'':
+			if i+1 < len(inner) && inner[i+1] == '\'' {
+				item.WriteByte(c)
+				i++
+				continue
+			}
+			quoted = false
+		case quoted:
+			item.WriteByte(c)
+		case c == '\'':
+			if strings.TrimSpace(item.String()) != "" {
+				return MaskArgs{}, &InvalidArgError{Arg: arg, Err: errors.New("unexpected quote")}
+			}
+			item.Reset()
+			quoted, wasQuot = true, true
+		case c == '=' && !named && !wasQuot:
+			name, named = strings.TrimSpace(item.String()), true
+			item.Reset()
+		case c == ',':
+			if err := flush(); err != nil {
+				return MaskArgs{}, &InvalidArgError{Arg: arg, Err: err}
+			}
+		case wasQuot:
+			if c != ' ' {
+				return MaskArgs{}, &InvalidArgError{Arg: arg, Err: errors.New("unexpected character after quoted value")}
+			}
+		default:
+			item.WriteByte(c)
+		}
+	}
+	if quoted {
+		return MaskArgs{}, &InvalidArgError{Arg: arg, Err: errors.New("unterminated quote")}
+	}
+	if err := flush(); err != nil {
+		return MaskArgs{}, &InvalidArgError{Arg: arg, Err: err}
+	}
+
+	return args, nil
+}
+
+// isStructuredArg reports whether the argument is written in parentheses.
+func isStructuredArg(arg string) bool {
+	return len(arg) >= 2 && arg[0] == '(' && arg[len(arg)-1] == ')'
+}
+
+// Raw returns the argument before parsing.
+func (a MaskArgs) Raw() string {
+	return a.raw
+}
+
+// Len returns the number of positional values.
+func (a MaskArgs) Len() int {
+	if a.single {
+		return 1
+	}
+	return len(a.positional)
+}
+
+// Index returns the i-th positional value.
+func (a MaskArgs) Index(i int) (string, bool) {
+	if a.single && i == 0 {
+		return a.raw, true
+	}
+	if i < 0 || i >= len(a.positional) {
+		return "", false
+	}
+	return a.positional[i], true
+}
+
+// Lookup returns the named value.
+func (a MaskArgs) Lookup(name string) (string, bool) {
+	v, ok := a.named[name]
+	return v, ok
+}
+
+// Get returns the named value, or the i-th positional value if the name is not given.
+// Empty positional values are treated as not given.
+func (a MaskArgs) Get(name string, i int) (string, bool) {
+	if v, ok := a.Lookup(name); ok {
+		return v, true
+	}
+	if v, ok := a.Index(i); ok && v != "" {
+		return v, true
+	}
+	return "", false
+}
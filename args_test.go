@@ -0,0 +1,198 @@
+package mask
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMaskArgs(t *testing.T) {
+	tests := map[string]struct {
+		arg        string
+		positional []string
+		named      map[string]string
+		isErr      bool
+	}{
+		"empty": {
+			arg: "",
+		},
+		"legacy": {
+			arg:        "100.3",
+			positional: []string{"100.3"},
+		},
+		"legacy with commas": {
+			arg:        "2,4",
+			positional: []string{"2", "4"},
+		},
+		"legacy with empty values": {
+			arg:        ",4",
+			positional: []string{"", "4"},
+		},
+		"legacy that is not closed by a parenthesis": {
+			arg:        "(最高).",
+			positional: []string{"(最高)."},
+		},
+		"empty parentheses": {
+			arg: "()",
+		},
+		"positional values": {
+			arg:        "(2, 4)",
+			positional: []string{"2", "4"},
+		},
+		"named values": {
+			arg:   "(keep=4, from = end)",
+			named: map[string]string{"keep": "4", "from": "end"},
+		},
+		"positional and named values": {
+			arg:        "(2,last=4)",
+			positional: []string{"2"},
+			named:      map[string]string{"last": "4"},
+		},
+		"quoted values": {
+			arg:        "('a,b', ' c ', pattern='(x)=''y''')",
+			positional: []string{"a,b", " c "},
+			named:      map[string]string{"pattern": "(x)='y'"},
+		},
+		"positional value after named value": {
+			arg:   "(keep=4,2)",
+			isErr: true,
+		},
+		"duplicate names": {
+			arg:   "(keep=4,keep=2)",
+			isErr: true,
+		},
+		"empty name": {
+			arg:   "(=4)",
+			isErr: true,
+		},
+		"unterminated quote": {
+			arg:   "('abc)",
+			isErr: true,
+		},
+		"character after quoted value": {
+			arg:   "('abc'd)",
+			isErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseMaskArgs(tt.arg)
+			if tt.isErr {
+				var argErr *InvalidArgError
+				if !errors.As(err, &argErr) {
+					t.Errorf("want an InvalidArgError, got %v", err)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.arg, got.Raw())
+			var positional []string
+			for i := 0; i < got.Len(); i++ {
+				v, _ := got.Index(i)
+				positional = append(positional, v)
+			}
+			if diff := cmp.Diff(tt.positional, positional); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.named, got.named); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestParseMaskArgs_Allocs(t *testing.T) {
+	for _, arg := range []string{"", "4", "100.3"} {
+		allocs := testing.AllocsPerRun(100, func() {
+			parseArgs(MaskTypeFilled, arg)
+		})
+		assert.Zero(t, allocs, arg)
+	}
+}
+
+func TestMaskArgs_Get(t *testing.T) {
+	args, err := ParseMaskArgs("(2,,last=4)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, args.Len())
+	v, ok := args.Get("first", 0)
+	assert.True(t, ok)
+	assert.Equal(t, "2", v)
+	_, ok = args.Get("middle", 1)
+	assert.False(t, ok)
+	v, ok = args.Get("last", 2)
+	assert.True(t, ok)
+	assert.Equal(t, "4", v)
+	_, ok = args.Index(2)
+	assert.False(t, ok)
+	_, ok = args.Lookup("first")
+	assert.False(t, ok)
+}
+
+func TestMask_StructuredTag(t *testing.T) {
+	type Test struct {
+		Filled  string  `mask:"filled(count=3)"`
+		Partial string  `mask:"partial(keep=4,from=end)"`
+		Both    string  `mask:"partial(1,last=1)"`
+		Email   string  `mask:"email(keep=1,domain=true)"`
+		Int     int     `mask:"random(100)"`
+		Int64   int64   `mask:"random(max=100)"`
+		Float64 float64 `mask:"random(max=100,digits=2)"`
+	}
+	input := Test{
+		Filled:  "ヤハッ！",
+		Partial: "4111111111111111",
+		Both:    "ヤハッ！",
+		Email:   "usagi@example.com",
+		Int:     1000,
+		Int64:   1000,
+		Float64: 1000,
+	}
+
+	m := newMasker()
+	got, err := m.Mask(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	masked := got.(Test)
+	assert.Equal(t, "***", masked.Filled)
+	assert.Equal(t, "************1111", masked.Partial)
+	assert.Equal(t, "ヤ**！", masked.Both)
+	assert.Equal(t, "u****@*******.com", masked.Email)
+	assert.True(t, 0 <= masked.Int && masked.Int < 100, masked.Int)
+	assert.True(t, 0 <= masked.Int64 && masked.Int64 < 100, masked.Int64)
+	assert.True(t, 0 <= masked.Float64 && masked.Float64 < 100, masked.Float64)
+
+	t.Run("exact mask type", func(t *testing.T) {
+		m := NewMasker()
+		m.RegisterMaskStringFunc("test", func(arg, value string) (string, error) {
+			return "test" + arg, nil
+		})
+		m.RegisterMaskStringFunc("test2", func(arg, value string) (string, error) {
+			return "test2" + arg, nil
+		})
+
+		got, err := m.String("test2(x)", "ヤハッ！")
+		assert.NoError(t, err)
+		assert.Equal(t, "test2(x)", got)
+	})
+	t.Run("invalid structured argument", func(t *testing.T) {
+		m := newMasker()
+		_, err := m.String("partial(keep=a)", "ヤハッ！")
+		var argErr *InvalidArgError
+		if assert.ErrorAs(t, err, &argErr) {
+			assert.Equal(t, MaskTypePartial, argErr.MaskType)
+		}
+		_, err = m.String("partial(keep=1,from=middle)", "ヤハッ！")
+		assert.ErrorAs(t, err, &argErr)
+		_, err = m.String("filled('3)", "ヤハッ！")
+		assert.ErrorAs(t, err, &argErr)
+	})
+}
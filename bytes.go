@@ -0,0 +1,75 @@
+package mask
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+var (
+	byteType       = reflect.TypeOf(byte(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// isBytes reports whether the type is a slice or an array of bytes.
+func isBytes(rt reflect.Type) bool {
+	kind := rt.Kind()
+	return (kind == reflect.Slice || kind == reflect.Array) && rt.Elem() == byteType
+}
+
+// maskBytes masks a slice or an array of bytes according to the BytesPolicy.
+// It reports false if the bytes are masked as elements.
+func (m *Masker) maskBytes(tag string, rv, mp reflect.Value) (bool, reflect.Value, error) {
+	rt := rv.Type()
+	if tag == "" || !isBytes(rt) {
+		return false, reflect.Value{}, nil
+	}
+
+	var rv2 reflect.Value
+	switch m.bytesPolicy {
+	case BytesAsElements:
+		return false, reflect.Value{}, nil
+	case BytesZero:
+		if rt.Kind() == reflect.Slice && rt != rawMessageType {
+			rv2 = reflect.MakeSlice(rt, rv.Len(), rv.Len())
+		} else {
+			// a nil json.RawMessage is encoded as null
+			rv2 = reflect.Zero(rt)
+		}
+	default:
+		f, arg, ok := m.maskStringFuncs.lookup(tag)
+		if !ok {
+			return false, reflect.Value{}, nil
+		}
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		s, err := f(arg, string(b))
+		if err != nil {
+			return true, reflect.Value{}, err
+		}
+		b = []byte(s)
+		if rt == rawMessageType {
+			// the masked value is not JSON anymore
+			if b, err = json.Marshal(s); err != nil {
+				return true, reflect.Value{}, err
+			}
+		}
+		rv2 = bytesValue(rt, b)
+	}
+
+	if mp.CanSet() {
+		mp.Set(rv2)
+		return true, mp, nil
+	}
+	return true, rv2, nil
+}
+
+// bytesValue converts b to a value of the type,
+// truncating or padding it with zeros to the length of an array.
+func bytesValue(rt reflect.Type, b []byte) reflect.Value {
+	if rt.Kind() == reflect.Slice {
+		return reflect.ValueOf(b).Convert(rt)
+	}
+	rv := reflect.New(rt).Elem()
+	reflect.Copy(rv, reflect.ValueOf(b))
+	return rv
+}
@@ -0,0 +1,134 @@
+package mask
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBytesPolicy(t *testing.T) {
+	type structTest struct {
+		Bytes    []byte          `mask:"filled"`
+		Raw      json.RawMessage `mask:"filled"`
+		Key      [16]byte        `mask:"filled"`
+		Short    [4]byte         `mask:"fixed"`
+		Nil      []byte          `mask:"filled"`
+		NoTag    []byte
+		RawNoTag json.RawMessage
+	}
+	input := structTest{
+		Bytes:    []byte("Usagi"),
+		Raw:      json.RawMessage(`{"name":"Chiikawa"}`),
+		Key:      [16]byte{'H', 'a', 'c', 'h', 'i', 'w', 'a', 'r', 'e'},
+		Short:    [4]byte{1, 2, 3, 4},
+		NoTag:    []byte("Momonga"),
+		RawNoTag: json.RawMessage(`"Kurimanju"`),
+	}
+
+	tests := map[string]struct {
+		policy BytesPolicy
+		want   structTest
+	}{
+		"as string": {
+			policy: BytesAsString,
+			want: structTest{
+				Bytes:    []byte("*****"),
+				Raw:      json.RawMessage(`"*******************"`),
+				Key:      [16]byte{'*', '*', '*', '*', '*', '*', '*', '*', '*', '*', '*', '*', '*', '*', '*', '*'},
+				Short:    [4]byte{'*', '*', '*', '*'},
+				NoTag:    []byte("Momonga"),
+				RawNoTag: json.RawMessage(`"Kurimanju"`),
+			},
+		},
+		"as elements": {
+			policy: BytesAsElements,
+			want: structTest{
+				Bytes:    []byte{0, 0, 0, 0, 0},
+				Raw:      make(json.RawMessage, 19),
+				Short:    [4]byte{1, 2, 3, 4},
+				NoTag:    []byte("Momonga"),
+				RawNoTag: json.RawMessage(`"Kurimanju"`),
+			},
+		},
+		"zero": {
+			policy: BytesZero,
+			want: structTest{
+				Bytes:    make([]byte, 5),
+				Short:    [4]byte{},
+				NoTag:    []byte("Momonga"),
+				RawNoTag: json.RawMessage(`"Kurimanju"`),
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			SetBytesPolicy(tt.policy)
+			RegisterMaskUintFunc(MaskTypeFilled, func(arg string, value uint) (uint, error) {
+				return 0, nil
+			})
+			got, err := Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+			assert.Equal(t, "Usagi", string(input.Bytes))
+		})
+	}
+
+	t.Run(newMaskerTestCase("raw message is valid JSON"), func(t *testing.T) {
+		for _, policy := range []BytesPolicy{BytesAsString, BytesZero} {
+			m := newMasker()
+			m.SetBytesPolicy(policy)
+			got, err := m.Mask(input)
+			assert.NoError(t, err)
+			_, err = json.Marshal(got)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run(newMaskerTestCase("hash of fixed-size key"), func(t *testing.T) {
+		m := newMasker()
+		key := [8]byte{'U', 's', 'a', 'g', 'i'}
+		got, err := m.Mask(struct {
+			Key [8]byte `mask:"hash"`
+		}{Key: key})
+		assert.NoError(t, err)
+		hash, err := m.MaskHashString("", string(key[:]))
+		assert.NoError(t, err)
+		var want [8]byte
+		copy(want[:], hash)
+		assert.Equal(t, want, got.(struct {
+			Key [8]byte `mask:"hash"`
+		}).Key)
+	})
+
+	t.Run(newMaskerTestCase("no function for string"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskUintFunc("max", func(arg string, value uint) (uint, error) {
+			return 255, nil
+		})
+		got, err := m.Mask(map[string][]byte{"Usagi": []byte("Usagi")})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"Usagi": []byte("Usagi")}, got)
+
+		m.RegisterMaskField("Usagi", "max")
+		got, err = m.Mask(map[string][]byte{"Usagi": []byte("Usagi")})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"Usagi": {255, 255, 255, 255, 255}}, got)
+	})
+
+	t.Run(newMaskerTestCase("validate"), func(t *testing.T) {
+		m := newMasker()
+		assert.NoError(t, m.Validate(structTest{}))
+		m.SetBytesPolicy(BytesAsElements)
+		assert.Error(t, m.Validate(structTest{}))
+		m.SetBytesPolicy(BytesZero)
+		assert.NoError(t, m.Validate(struct {
+			Bytes []byte `mask:"fixed"`
+		}{}))
+	})
+}
@@ -0,0 +1,114 @@
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrHashKeyRequired is returned when an HMAC hash algorithm is used without a key.
+	ErrHashKeyRequired = errors.New("mask: hash key is required for HMAC algorithms")
+	// ErrPseudoKeyRequired is returned when a pseudo mask type is used without a key.
+	ErrPseudoKeyRequired = errors.New("mask: pseudo key is required")
+	// ErrUnregisteredMaskType is reported when no function is registered for the mask type of a tag.
+	ErrUnregisteredMaskType = errors.New("mask: unregistered mask type")
+	// ErrIncompatibleMaskType is reported when the mask type of a tag has no function for the kind of the value.
+	ErrIncompatibleMaskType = errors.New("mask: mask type is incompatible with the kind of the value")
+	// ErrInvalidJSONPath is returned when a path given to RegisterMaskJSONPath cannot be parsed.
+	ErrInvalidJSONPath = errors.New("mask: invalid JSON path")
+)
+
+// JSONSyntaxError is returned when the input of MaskJSON or MaskJSONStream is not valid JSON.
+type JSONSyntaxError struct {
+	// Offset is the number of bytes read before the error.
+	Offset int64
+	msg    string
+}
+
+func (e *JSONSyntaxError) Error() string {
+	return fmt.Sprintf("mask: invalid JSON at offset %d: %s", e.Offset, e.msg)
+}
+
+// InvalidArgError is returned when the argument given to a mask type cannot be interpreted.
+type InvalidArgError struct {
+	MaskType string
+	Arg      string
+	Err      error
+}
+
+func (e *InvalidArgError) Error() string {
+	if e.MaskType == "" {
+		return fmt.Sprintf("mask: invalid argument %q: %v", e.Arg, e.Err)
+	}
+	return fmt.Sprintf("mask: invalid argument %q for %s: %v", e.Arg, e.MaskType, e.Err)
+}
+
+func (e *InvalidArgError) Unwrap() error {
+	return e.Err
+}
+
+// OverflowError is returned when a masked value does not fit in the type of the value.
+type OverflowError struct {
+	Tag   string
+	Value any
+	Type  reflect.Type
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("mask: masked value %v of %q overflows %s", e.Value, e.Tag, e.Type)
+}
+
+// UnmatchedTagError is returned under UnmatchedFail when a mask tag cannot be applied to a value.
+type UnmatchedTagError struct {
+	Tag  string
+	Type reflect.Type
+	// Err is ErrUnregisteredMaskType or ErrIncompatibleMaskType.
+	Err error
+}
+
+func (e *UnmatchedTagError) Error() string {
+	return fmt.Sprintf("mask: tag %q cannot be applied to %s: %v", e.Tag, e.Type, e.Err)
+}
+
+func (e *UnmatchedTagError) Unwrap() error {
+	return e.Err
+}
+
+// TagError describes a mask tag of a struct field that cannot be applied.
+type TagError struct {
+	// Path is the path of the field from the validated type, like "main.User.Address.PostCode".
+	Path string
+	Tag  string
+	Err  error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("%s: tag %q: %v", e.Path, e.Tag, e.Err)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned by Validate and holds every mask tag that cannot be applied.
+type ValidationError struct {
+	Errors []*TagError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("mask: %d invalid tags: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -50,3 +50,27 @@ func Example_customMaskFunc() {
 	// Output:
 	// "これって…■■じゃん", Hachiware says
 }
+
+func ExampleParseMaskArgs() {
+	masker := mask.NewMasker()
+	masker.RegisterMaskStringFunc("wrap", func(arg, value string) (string, error) {
+		args, err := mask.ParseMaskArgs(arg)
+		if err != nil {
+			return "", err
+		}
+		left, _ := args.Get("left", 0)
+		right, _ := args.Get("right", 1)
+		return left + strings.Repeat(masker.MaskChar(), utf8.RuneCountInString(value)) + right, nil
+	})
+
+	type Hachiware struct {
+		Message string `mask:"wrap(left='[', right=']')"`
+	}
+
+	input := Hachiware{Message: "最高じゃん"}
+	got, _ := masker.Mask(input)
+	fmt.Printf("%+v\n", got)
+
+	// Output:
+	// {Message:[*****]}
+}
@@ -0,0 +1,157 @@
+package mask
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// RegisterMaskFieldFold registers a mask tag to be applied to the values of the fields and map keys
+// that match the fieldName case-insensitively
+// from default masker.
+func RegisterMaskFieldFold(fieldName, maskType string) {
+	defaultMasker.RegisterMaskFieldFold(fieldName, maskType)
+}
+
+// RegisterMaskFieldNormalized registers a mask tag to be applied to the values of the fields and map keys
+// that match the fieldName regardless of the case and the separators
+// from default masker.
+func RegisterMaskFieldNormalized(fieldName, maskType string) {
+	defaultMasker.RegisterMaskFieldNormalized(fieldName, maskType)
+}
+
+// RegisterMaskFieldPattern registers a mask tag to be applied to the values of the fields and map keys
+// that match the glob pattern
+// from default masker.
+func RegisterMaskFieldPattern(pattern, maskType string) error {
+	return defaultMasker.RegisterMaskFieldPattern(pattern, maskType)
+}
+
+// RegisterMaskFieldRegexp registers a mask tag to be applied to the values of the fields and map keys
+// that match the regular expression
+// from default masker.
+func RegisterMaskFieldRegexp(re *regexp.Regexp, maskType string) {
+	defaultMasker.RegisterMaskFieldRegexp(re, maskType)
+}
+
+// fieldRules holds the rules for field names other than the exact names registered by RegisterMaskField.
+// The zero value has no rules.
+type fieldRules struct {
+	// fold holds the tags by the names in lower case.
+	fold map[string]string
+	// normalized holds the tags by the names from normalizeFieldName.
+	normalized map[string]string
+	// patterns holds the glob patterns and regular expressions in the order of registration.
+	patterns []fieldPattern
+}
+
+type fieldPattern struct {
+	// pattern is the glob pattern, or the source text of the regular expression.
+	pattern  string
+	re       *regexp.Regexp
+	maskType string
+}
+
+func (p fieldPattern) match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	ok, _ := path.Match(p.pattern, name)
+	return ok
+}
+
+// RegisterMaskFieldFold registers a mask tag to be applied to the values of the fields and map keys
+// that match the fieldName case-insensitively, such as "password" for "Password" and "PASSWORD".
+// The rules are applied to the names that have no tag registered by RegisterMaskField, in the order of
+// RegisterMaskFieldFold, RegisterMaskFieldNormalized, and RegisterMaskFieldPattern and RegisterMaskFieldRegexp.
+// With SetFieldNameTag, the tag of a struct field is resolved in the order of the mask tag of the field,
+// RegisterMaskField for the name in Go, RegisterMaskField for the name in the field name tag,
+// the rules for the name in Go, and the rules for the name in the field name tag.
+func (m *Masker) RegisterMaskFieldFold(fieldName, maskType string) {
+	if m.fieldRules.fold == nil {
+		m.fieldRules.fold = make(map[string]string)
+	}
+	m.fieldRules.fold[strings.ToLower(fieldName)] = maskType
+	m.typeToStructCache.reset()
+}
+
+// RegisterMaskFieldNormalized registers a mask tag to be applied to the values of the fields and map keys
+// that match the fieldName regardless of the case and the characters other than letters and digits,
+// so that "user_password" matches "UserPassword", "userPassword" and "user-password".
+// See RegisterMaskFieldFold for the precedence of the rules.
+func (m *Masker) RegisterMaskFieldNormalized(fieldName, maskType string) {
+	if m.fieldRules.normalized == nil {
+		m.fieldRules.normalized = make(map[string]string)
+	}
+	m.fieldRules.normalized[normalizeFieldName(fieldName)] = maskType
+	m.typeToStructCache.reset()
+}
+
+// RegisterMaskFieldPattern registers a mask tag to be applied to the values of the fields and map keys
+// that match the glob pattern, such as "*_token", in the syntax of path.Match.
+// If several patterns or regular expressions match a name, the one registered first is applied,
+// and registering a pattern again replaces its mask type.
+// See RegisterMaskFieldFold for the precedence of the rules.
+func (m *Masker) RegisterMaskFieldPattern(pattern, maskType string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	m.registerFieldPattern(fieldPattern{pattern: pattern, maskType: maskType})
+
+	return nil
+}
+
+// RegisterMaskFieldRegexp registers a mask tag to be applied to the values of the fields and map keys
+// that match the regular expression, such as `(?i)^x-api-key$`.
+// The regular expressions share the order of precedence with the patterns of RegisterMaskFieldPattern.
+func (m *Masker) RegisterMaskFieldRegexp(re *regexp.Regexp, maskType string) {
+	m.registerFieldPattern(fieldPattern{pattern: re.String(), re: re, maskType: maskType})
+}
+
+func (m *Masker) registerFieldPattern(p fieldPattern) {
+	defer m.typeToStructCache.reset()
+	for i, registered := range m.fieldRules.patterns {
+		if registered.pattern == p.pattern && (registered.re == nil) == (p.re == nil) {
+			m.fieldRules.patterns[i] = p
+			return
+		}
+	}
+	m.fieldRules.patterns = append(m.fieldRules.patterns, p)
+}
+
+// hasFieldRules reports whether any tag is registered for field names.
+func (m *Masker) hasFieldRules() bool {
+	return len(m.maskFieldMap) > 0 || len(m.fieldRules.fold) > 0 ||
+		len(m.fieldRules.normalized) > 0 || len(m.fieldRules.patterns) > 0
+}
+
+// lookupFieldRules returns the tag of the first rule that matches the name.
+func (m *Masker) lookupFieldRules(name string) string {
+	if len(m.fieldRules.fold) > 0 {
+		if tag := m.fieldRules.fold[strings.ToLower(name)]; tag != "" {
+			return tag
+		}
+	}
+	if len(m.fieldRules.normalized) > 0 {
+		if tag := m.fieldRules.normalized[normalizeFieldName(name)]; tag != "" {
+			return tag
+		}
+	}
+	for _, p := range m.fieldRules.patterns {
+		if p.match(name) {
+			return p.maskType
+		}
+	}
+	return ""
+}
+
+// normalizeFieldName converts the name to lower case and drops the characters other than letters and digits.
+func normalizeFieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
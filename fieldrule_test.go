@@ -0,0 +1,186 @@
+package mask
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterMaskFieldRules(t *testing.T) {
+	type structTest struct {
+		Password     string
+		UserPassword string
+		AccessToken  string
+		Name         string
+		Extra        map[string]string
+	}
+	input := structTest{
+		Password:     "Usagi",
+		UserPassword: "Usagi",
+		AccessToken:  "Usagi",
+		Name:         "Usagi",
+		Extra: map[string]string{
+			"password":      "Chiikawa",
+			"PASSWORD":      "Chiikawa",
+			"user_password": "Chiikawa",
+			"user-password": "Chiikawa",
+			"refresh_token": "Chiikawa",
+			"X-Api-Key":     "Chiikawa",
+			"x-api-key":     "Chiikawa",
+			"name":          "Chiikawa",
+		},
+	}
+	want := structTest{
+		Password:     "*****",
+		UserPassword: "********",
+		AccessToken:  "Usagi",
+		Name:         "Usagi",
+		Extra: map[string]string{
+			"password":      "********",
+			"PASSWORD":      "********",
+			"user_password": "********",
+			"user-password": "********",
+			"refresh_token": "********",
+			"X-Api-Key":     "********",
+			"x-api-key":     "********",
+			"name":          "Chiikawa",
+		},
+	}
+	register := func(m *Masker) {
+		m.RegisterMaskFieldFold("password", MaskTypeFilled)
+		m.RegisterMaskFieldNormalized("user_password", MaskTypeFixed)
+		assert.NoError(t, m.RegisterMaskFieldPattern("*_token", MaskTypeFixed))
+		m.RegisterMaskFieldRegexp(regexp.MustCompile(`(?i)^x-api-key$`), MaskTypeFixed)
+	}
+
+	t.Run(defaultTestCase("rules"), func(t *testing.T) {
+		defer cleanup(t)
+		RegisterMaskFieldFold("password", MaskTypeFilled)
+		RegisterMaskFieldNormalized("user_password", MaskTypeFixed)
+		assert.NoError(t, RegisterMaskFieldPattern("*_token", MaskTypeFixed))
+		RegisterMaskFieldRegexp(regexp.MustCompile(`(?i)^x-api-key$`), MaskTypeFixed)
+		got, err := Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+	for _, cache := range []bool{true, false} {
+		t.Run(newMaskerTestCase("rules"), func(t *testing.T) {
+			m := newMasker()
+			m.Cache(cache)
+			register(m)
+			got, err := m.Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run(newMaskerTestCase("precedence"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskField("Password", MaskTypeZero)
+		m.RegisterMaskFieldFold("password", MaskTypeFilled)
+		m.RegisterMaskFieldNormalized("pass_word", MaskTypeFixed)
+		assert.NoError(t, m.RegisterMaskFieldPattern("Pass*", MaskTypeHash))
+		m.RegisterMaskFieldRegexp(regexp.MustCompile(`^Pass`), MaskTypeZero)
+		assert.NoError(t, m.RegisterMaskFieldPattern("Pass?", MaskTypeFixed))
+
+		tests := map[string]string{
+			"Password":  MaskTypeZero,
+			"PASSWORD":  MaskTypeFilled,
+			"Pass_Word": MaskTypeFixed,
+			"Passcode":  MaskTypeHash,
+			"Passw":     MaskTypeHash,
+			"Name":      "",
+		}
+		for name, want := range tests {
+			assert.Equal(t, want, m.getTag("", name), name)
+		}
+		assert.Equal(t, "explicit", m.getTag("explicit", "Password"))
+
+		// registering a pattern again replaces its mask type but keeps the precedence
+		assert.NoError(t, m.RegisterMaskFieldPattern("Pass*", MaskTypePartial))
+		assert.Equal(t, MaskTypePartial, m.getTag("", "Passcode"))
+	})
+
+	t.Run(newMaskerTestCase("precedence with field name tag"), func(t *testing.T) {
+		m := newMasker()
+		m.SetFieldNameTag("json")
+		m.RegisterMaskField("card_number", MaskTypeFilled)
+		m.RegisterMaskField("Holder", MaskTypeZero)
+		assert.NoError(t, m.RegisterMaskFieldPattern("*Number", MaskTypeHash))
+		assert.NoError(t, m.RegisterMaskFieldPattern("*_code", MaskTypeFixed))
+		m.RegisterMaskFieldFold("holder_name", MaskTypePartial)
+
+		tests := map[string]struct {
+			field reflect.StructField
+			want  string
+		}{
+			"mask tag": {
+				field: reflect.StructField{Name: "CardNumber", Tag: `mask:"email" json:"card_number"`},
+				want:  MaskTypeEmail,
+			},
+			"exact name in Go before exact json name": {
+				field: reflect.StructField{Name: "Holder", Tag: `json:"card_number"`},
+				want:  MaskTypeZero,
+			},
+			"exact json name before a rule for the name in Go": {
+				field: reflect.StructField{Name: "CardNumber", Tag: `json:"card_number"`},
+				want:  MaskTypeFilled,
+			},
+			"rule for the name in Go before a rule for the json name": {
+				field: reflect.StructField{Name: "CardNumber", Tag: `json:"security_code"`},
+				want:  MaskTypeHash,
+			},
+			"rule for the json name": {
+				field: reflect.StructField{Name: "Name", Tag: `json:"HOLDER_NAME"`},
+				want:  MaskTypePartial,
+			},
+			"no name on the wire": {
+				field: reflect.StructField{Name: "Code", Tag: `json:"-"`},
+				want:  "",
+			},
+		}
+		for name, tt := range tests {
+			assert.Equal(t, tt.want, m.getFieldTag(tt.field), name)
+		}
+	})
+
+	t.Run(newMaskerTestCase("json"), func(t *testing.T) {
+		m := newMasker()
+		register(m)
+		got, err := m.MaskJSON([]byte(`{"Password": "Usagi", "refresh_token": "Usagi", "name": "Usagi"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"Password": "*****", "refresh_token": "********", "name": "Usagi"}`, string(got))
+	})
+
+	t.Run(newMaskerTestCase("copy policy"), func(t *testing.T) {
+		m := newMasker()
+		m.SetCopyPolicy(CopyShare)
+		input := []map[string]string{{"api_token": "Usagi"}}
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, input, got)
+
+		assert.NoError(t, m.RegisterMaskFieldPattern("*_token", MaskTypeFilled))
+		got, err = m.Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]string{{"api_token": "*****"}}, got)
+	})
+
+	t.Run(newMaskerTestCase("invalid pattern"), func(t *testing.T) {
+		assert.ErrorIs(t, newMasker().RegisterMaskFieldPattern("[", MaskTypeFilled), path.ErrBadPattern)
+	})
+}
+
+func TestNormalizeFieldName(t *testing.T) {
+	for _, name := range []string{"user_password", "UserPassword", "userPassword", "user-password", "USER PASSWORD", "User.Password"} {
+		assert.Equal(t, "userpassword", normalizeFieldName(name), name)
+	}
+}
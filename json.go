@@ -0,0 +1,617 @@
+package mask
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxJSONDepth limits the nesting of JSON values, so that a malicious document cannot exhaust the stack.
+const maxJSONDepth = 10000
+
+// MaskJSON returns a copy of the JSON document with the mask applied
+// from default masker.
+func MaskJSON(data []byte) ([]byte, error) {
+	return defaultMasker.MaskJSON(data)
+}
+
+// MaskJSONStream masks the stream of JSON values read from src and writes them to dst
+// from default masker.
+func MaskJSONStream(dst io.Writer, src io.Reader) error {
+	return defaultMasker.MaskJSONStream(dst, src)
+}
+
+// RegisterMaskJSONPath registers a mask tag to be applied to the JSON values at the path by MaskJSON
+// from default masker.
+func RegisterMaskJSONPath(path, maskType string) error {
+	return defaultMasker.RegisterMaskJSONPath(path, maskType)
+}
+
+// MaskJSON returns a copy of the JSON document with the mask applied, without decoding it into Go values.
+// The tags registered for the keys by RegisterMaskField and for the paths by RegisterMaskJSONPath are applied to the values,
+// and the tag of an object or an array is applied to the values inside, as a tag on a map or a slice.
+// The order of the keys, the whitespace and the literals of the values that are not masked are kept as they are.
+// A string is masked with the functions for string.
+// A number is masked with the functions for int64 if it is an integer, or for float64 otherwise,
+// and if the mask type has no function for the number, it is masked with the functions for string and written as a string.
+// The data must hold exactly one JSON value.
+func (m *Masker) MaskJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	if err := m.newJSONScanner(&buf, bytes.NewReader(data)).scan(true); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MaskJSONStream masks the JSON values read from src, like MaskJSON, and writes them to dst.
+// src can hold any number of JSON values separated by whitespace, such as newline-delimited JSON,
+// and each value is written to dst as soon as it is masked.
+func (m *Masker) MaskJSONStream(dst io.Writer, src io.Reader) error {
+	return m.newJSONScanner(dst, src).scan(false)
+}
+
+// RegisterMaskJSONPath registers a mask tag to be applied to the JSON values at the path by MaskJSON and MaskJSONStream.
+// The path is written in a subset of JSONPath:
+//
+//	$              the root value
+//	.name, ['name'] the member of an object with the name
+//	[0]            the element of an array at the index
+//	.*, [*]        every member or element
+//	..name, ..*    the members with the name, or every value, at any depth below
+//
+// A tag registered for a path takes precedence over the tag registered for the key by RegisterMaskField.
+// If several paths match a value, the one registered first is applied.
+// Registering a path again replaces its mask type.
+func (m *Masker) RegisterMaskJSONPath(path, maskType string) error {
+	segs, err := parseJSONPath(path)
+	if err != nil {
+		return err
+	}
+	for i, rule := range m.jsonPaths {
+		if rule.path == path {
+			m.jsonPaths[i].maskType = maskType
+			return nil
+		}
+	}
+	m.jsonPaths = append(m.jsonPaths, jsonPathRule{path: path, segs: segs, maskType: maskType})
+
+	return nil
+}
+
+// jsonPathRule is a path registered by RegisterMaskJSONPath.
+type jsonPathRule struct {
+	path     string
+	segs     []jsonPathSeg
+	maskType string
+}
+
+// jsonPathSeg is a segment of a path.
+type jsonPathSeg struct {
+	// key is the name of a member if index is -1.
+	key   string
+	index int
+	// wildcard matches every member or element.
+	wildcard bool
+	// descent matches the segment at any depth below.
+	descent bool
+}
+
+func (seg jsonPathSeg) match(elem jsonPathElem) bool {
+	switch {
+	case seg.wildcard:
+		return true
+	case seg.index < 0:
+		return elem.index < 0 && elem.key == seg.key
+	default:
+		return elem.index == seg.index
+	}
+}
+
+// jsonPathElem is a step from a JSON value to a value inside.
+type jsonPathElem struct {
+	// key is the name of a member if index is -1.
+	key   string
+	index int
+}
+
+func matchJSONPath(segs []jsonPathSeg, path []jsonPathElem) bool {
+	if len(segs) == 0 {
+		return len(path) == 0
+	}
+	seg := segs[0]
+	if seg.descent {
+		for i := range path {
+			if seg.match(path[i]) && matchJSONPath(segs[1:], path[i+1:]) {
+				return true
+			}
+		}
+		return false
+	}
+	return len(path) > 0 && seg.match(path[0]) && matchJSONPath(segs[1:], path[1:])
+}
+
+func parseJSONPath(path string) ([]jsonPathSeg, error) {
+	invalid := func() ([]jsonPathSeg, error) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidJSONPath, path)
+	}
+	if !strings.HasPrefix(path, "$") {
+		return invalid()
+	}
+
+	var segs []jsonPathSeg
+	p := path[1:]
+	for len(p) > 0 {
+		seg := jsonPathSeg{index: -1}
+		switch {
+		case strings.HasPrefix(p, ".."):
+			seg.descent = true
+			p = p[2:]
+		case p[0] == '.':
+			p = p[1:]
+		case p[0] != '[':
+			return invalid()
+		}
+
+		if strings.HasPrefix(p, "[") {
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return invalid()
+			}
+			inner := p[1:end]
+			p = p[end+1:]
+			switch {
+			case inner == "*":
+				seg.wildcard = true
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				seg.key = inner[1 : len(inner)-1]
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return invalid()
+				}
+				seg.index = index
+			}
+		} else {
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return invalid()
+			}
+			seg.key = p[:end]
+			seg.wildcard = seg.key == "*"
+			p = p[end:]
+		}
+		segs = append(segs, seg)
+	}
+
+	return segs, nil
+}
+
+// jsonScanner copies JSON values from r to w, masking the values that have a tag.
+type jsonScanner struct {
+	m      *Masker
+	r      *bufio.Reader
+	w      *bufio.Writer
+	offset int64
+	path   []jsonPathElem
+	cb     localCircuitBreaker
+}
+
+func (m *Masker) newJSONScanner(w io.Writer, r io.Reader) *jsonScanner {
+	return &jsonScanner{
+		m:  m,
+		r:  bufio.NewReader(r),
+		w:  bufio.NewWriter(w),
+		cb: localCircuitBreaker{},
+	}
+}
+
+// scan masks the values until the end of the input.
+// If single is true, the input must hold exactly one value.
+func (s *jsonScanner) scan(single bool) error {
+	n := 0
+	for ; ; n++ {
+		c, err := s.space()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if single && n > 0 {
+			return s.syntaxError(fmt.Sprintf("invalid character %q after top-level value", c))
+		}
+		if err := s.value(s.pathTag(""), 0); err != nil {
+			return err
+		}
+		if err := s.w.Flush(); err != nil {
+			return err
+		}
+	}
+	if single && n == 0 {
+		return s.syntaxError("unexpected end of JSON input")
+	}
+
+	return s.w.Flush()
+}
+
+// value masks the value starting at the next byte with the tag.
+func (s *jsonScanner) value(tag string, depth int) error {
+	if depth > maxJSONDepth {
+		return s.syntaxError("exceeded max depth")
+	}
+	c, err := s.peek()
+	if err != nil {
+		return err
+	}
+
+	var lit []byte
+	switch {
+	case c == '{':
+		return s.object(tag, depth)
+	case c == '[':
+		return s.array(tag, depth)
+	case c == '"':
+		lit, err = s.readString()
+	case c == '-' || '0' <= c && c <= '9':
+		lit, err = s.readNumber()
+	case c == 't' || c == 'f' || c == 'n':
+		lit, err = s.readLiteral()
+	default:
+		return s.syntaxError(fmt.Sprintf("invalid character %q looking for beginning of value", c))
+	}
+	if err != nil {
+		return err
+	}
+
+	if tag != "" {
+		if lit, err = s.maskLiteral(tag, lit); err != nil {
+			return err
+		}
+	}
+	_, err = s.w.Write(lit)
+	return err
+}
+
+func (s *jsonScanner) object(tag string, depth int) error {
+	if err := s.copyByte(); err != nil {
+		return err
+	}
+	c, err := s.space()
+	if err != nil {
+		return s.unexpectedEOF(err)
+	}
+	if c == '}' {
+		return s.copyByte()
+	}
+
+	for {
+		if c != '"' {
+			return s.syntaxError(fmt.Sprintf("invalid character %q looking for beginning of object key string", c))
+		}
+		raw, err := s.readString()
+		if err != nil {
+			return err
+		}
+		var key string
+		if err := json.Unmarshal(raw, &key); err != nil {
+			return s.syntaxError(err.Error())
+		}
+		if _, err := s.w.Write(raw); err != nil {
+			return err
+		}
+		if c, err = s.space(); err != nil {
+			return s.unexpectedEOF(err)
+		}
+		if c != ':' {
+			return s.syntaxError(fmt.Sprintf("invalid character %q after object key", c))
+		}
+		if err := s.copyByte(); err != nil {
+			return err
+		}
+		if _, err := s.space(); err != nil {
+			return s.unexpectedEOF(err)
+		}
+
+		// the rules for the member take precedence over the tag of the object
+		memberTag := s.pathTag(key)
+		if memberTag == "" {
+			memberTag = s.m.getTag("", key)
+		}
+		if memberTag == "" {
+			memberTag = tag
+		}
+		s.path = append(s.path, jsonPathElem{key: key, index: -1})
+		if err := s.value(memberTag, depth+1); err != nil {
+			return err
+		}
+		s.path = s.path[:len(s.path)-1]
+
+		if c, err = s.space(); err != nil {
+			return s.unexpectedEOF(err)
+		}
+		if err := s.copyByte(); err != nil {
+			return err
+		}
+		switch c {
+		case '}':
+			return nil
+		case ',':
+		default:
+			return s.syntaxError(fmt.Sprintf("invalid character %q after object key:value pair", c))
+		}
+		if c, err = s.space(); err != nil {
+			return s.unexpectedEOF(err)
+		}
+	}
+}
+
+func (s *jsonScanner) array(tag string, depth int) error {
+	if err := s.copyByte(); err != nil {
+		return err
+	}
+	c, err := s.space()
+	if err != nil {
+		return s.unexpectedEOF(err)
+	}
+	if c == ']' {
+		return s.copyByte()
+	}
+
+	for i := 0; ; i++ {
+		s.path = append(s.path, jsonPathElem{index: i})
+		elemTag := s.pathTag("")
+		if elemTag == "" {
+			elemTag = tag
+		}
+		if err := s.value(elemTag, depth+1); err != nil {
+			return err
+		}
+		s.path = s.path[:len(s.path)-1]
+
+		if c, err = s.space(); err != nil {
+			return s.unexpectedEOF(err)
+		}
+		if err := s.copyByte(); err != nil {
+			return err
+		}
+		switch c {
+		case ']':
+			return nil
+		case ',':
+		default:
+			return s.syntaxError(fmt.Sprintf("invalid character %q after array element", c))
+		}
+		if _, err = s.space(); err != nil {
+			return s.unexpectedEOF(err)
+		}
+	}
+}
+
+// pathTag returns the tag registered for the current path, followed by the member with the key if key is not empty.
+func (s *jsonScanner) pathTag(key string) string {
+	if len(s.m.jsonPaths) == 0 {
+		return ""
+	}
+	path := s.path
+	if key != "" {
+		path = append(path, jsonPathElem{key: key, index: -1})
+	}
+	for _, rule := range s.m.jsonPaths {
+		if matchJSONPath(rule.segs, path) {
+			return rule.maskType
+		}
+	}
+	return ""
+}
+
+// maskLiteral masks the literal of a string, a number or a boolean with the tag.
+func (s *jsonScanner) maskLiteral(tag string, lit []byte) ([]byte, error) {
+	switch lit[0] {
+	case 'n':
+		// null has nothing to mask
+		return lit, nil
+	case 't', 'f':
+		return s.maskValue(tag, reflect.ValueOf(lit[0] == 't'))
+	case '"':
+		var v string
+		if err := json.Unmarshal(lit, &v); err != nil {
+			return nil, s.syntaxError(err.Error())
+		}
+		return s.maskValue(tag, reflect.ValueOf(v))
+	}
+
+	m := s.m
+	if i, err := strconv.ParseInt(string(lit), 10, 64); err == nil {
+		if _, ok := m.lookupIntFunc(tag, reflect.TypeOf(i)); ok || m.maskAnyFuncs.has(tag) {
+			return s.maskValue(tag, reflect.ValueOf(i))
+		}
+	} else if f, err := strconv.ParseFloat(string(lit), 64); err == nil {
+		if _, ok := m.lookupFloatFunc(tag, reflect.Float64); ok || m.maskAnyFuncs.has(tag) {
+			return s.maskValue(tag, reflect.ValueOf(f))
+		}
+	}
+	// such as a card number masked by filled
+	if m.maskStringFuncs.has(tag) {
+		return s.maskValue(tag, reflect.ValueOf(string(lit)))
+	}
+	switch m.unmatchedPolicy {
+	case UnmatchedFail:
+		return nil, m.unmatchedTagError(tag, reflect.TypeOf(json.Number("")))
+	case UnmatchedZero:
+		return []byte("0"), nil
+	default:
+		return lit, nil
+	}
+}
+
+func (s *jsonScanner) maskValue(tag string, rv reflect.Value) ([]byte, error) {
+	rv2, err := s.m.mask(rv, tag, reflect.Value{}, s.cb)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(rv2.Interface()); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// space copies the whitespace and returns the next byte without reading it.
+func (s *jsonScanner) space() (byte, error) {
+	for {
+		c, err := s.peek()
+		if err != nil {
+			return 0, err
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return c, nil
+		}
+		if err := s.copyByte(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (s *jsonScanner) peek() (byte, error) {
+	b, err := s.r.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (s *jsonScanner) readByte() (byte, error) {
+	c, err := s.r.ReadByte()
+	if err != nil {
+		return 0, s.unexpectedEOF(err)
+	}
+	s.offset++
+	return c, nil
+}
+
+func (s *jsonScanner) copyByte() error {
+	c, err := s.readByte()
+	if err != nil {
+		return err
+	}
+	return s.w.WriteByte(c)
+}
+
+// readString reads a string literal including the quotes.
+func (s *jsonScanner) readString() ([]byte, error) {
+	c, err := s.readByte()
+	if err != nil {
+		return nil, err
+	}
+	lit := []byte{c}
+	for {
+		if c, err = s.readByte(); err != nil {
+			return nil, err
+		}
+		lit = append(lit, c)
+		switch {
+		case c == '"':
+			return lit, nil
+		case c < 0x20:
+			return nil, s.syntaxError(fmt.Sprintf("invalid character %q in string literal", c))
+		case c == '\\':
+			if c, err = s.readByte(); err != nil {
+				return nil, err
+			}
+			lit = append(lit, c)
+			switch c {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				for i := 0; i < 4; i++ {
+					if c, err = s.readByte(); err != nil {
+						return nil, err
+					}
+					if !isHex(c) {
+						return nil, s.syntaxError(fmt.Sprintf("invalid character %q in \\u hexadecimal character escape", c))
+					}
+					lit = append(lit, c)
+				}
+			default:
+				return nil, s.syntaxError(fmt.Sprintf("invalid character %q in string escape code", c))
+			}
+		}
+	}
+}
+
+func (s *jsonScanner) readNumber() ([]byte, error) {
+	lit, err := s.readWhile(func(c byte) bool {
+		return '0' <= c && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(lit) {
+		return nil, s.syntaxError(fmt.Sprintf("invalid number literal %q", lit))
+	}
+	return lit, nil
+}
+
+func (s *jsonScanner) readLiteral() ([]byte, error) {
+	lit, err := s.readWhile(func(c byte) bool {
+		return 'a' <= c && c <= 'z'
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch string(lit) {
+	case "true", "false", "null":
+		return lit, nil
+	}
+	return nil, s.syntaxError(fmt.Sprintf("invalid literal %q", lit))
+}
+
+// readWhile reads the bytes while f reports true, stopping at the end of the input.
+func (s *jsonScanner) readWhile(f func(c byte) bool) ([]byte, error) {
+	var lit []byte
+	for {
+		c, err := s.peek()
+		if errors.Is(err, io.EOF) {
+			return lit, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !f(c) {
+			return lit, nil
+		}
+		if _, err := s.readByte(); err != nil {
+			return nil, err
+		}
+		lit = append(lit, c)
+	}
+}
+
+// unexpectedEOF converts io.EOF in the middle of a value to a *JSONSyntaxError.
+func (s *jsonScanner) unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return s.syntaxError("unexpected end of JSON input")
+	}
+	return err
+}
+
+func (s *jsonScanner) syntaxError(msg string) error {
+	return &JSONSyntaxError{Offset: s.offset, msg: msg}
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
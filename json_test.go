@@ -0,0 +1,272 @@
+package mask
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskJSON(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"field name": {
+			input: `{"name": "Usagi", "password": "Chiikawa", "id": 1}`,
+			want:  `{"name": "Usagi", "password": "********", "id": 1}`,
+		},
+		"formatting and key order": {
+			input: "{\n\t\"z\": 1,\n\t\"password\" :\"Usagi\" ,\n\t\"a\": [ 1.50, 2e10 ]\n}\n",
+			want:  "{\n\t\"z\": 1,\n\t\"password\" :\"*****\" ,\n\t\"a\": [ 1.50, 2e10 ]\n}\n",
+		},
+		"number precision": {
+			input: `{"amount": 12345678901234567890.123456789, "id": 9007199254740993}`,
+			want:  `{"amount": 12345678901234567890.123456789, "id": 9007199254740993}`,
+		},
+		"nested object": {
+			input: `{"user": {"password": "Usagi", "name": "Usagi"}}`,
+			want:  `{"user": {"password": "*****", "name": "Usagi"}}`,
+		},
+		"tag on object and array": {
+			input: `{"secret": {"a": "Usagi", "b": ["Chiikawa", null, true]}}`,
+			want:  `{"secret": {"a": "*****", "b": ["********", null, true]}}`,
+		},
+		"number masked as string": {
+			input: `{"card": 4111111111111111}`,
+			want:  `{"card": "****************"}`,
+		},
+		"number masked as number": {
+			input: `{"age": 3, "score": 1.5}`,
+			want:  `{"age": 0, "score": 0}`,
+		},
+		"escaped string": {
+			input: `{"password": "うさぎ<>", "name": "\"Usagi\""}`,
+			want:  `{"password": "*****", "name": "\"Usagi\""}`,
+		},
+		"html is not escaped": {
+			input: `{"partial": "<Usagi>"}`,
+			want:  `{"partial": "<******"}`,
+		},
+		"top-level array": {
+			input: `[{"password": "Usagi"}, {"password": "Chiikawa"}]`,
+			want:  `[{"password": "*****"}, {"password": "********"}]`,
+		},
+		"scalar": {
+			input: `"Usagi"`,
+			want:  `"Usagi"`,
+		},
+		"empty": {
+			input: `{"password": "", "secret": {}, "card": []}`,
+			want:  `{"password": "", "secret": {}, "card": []}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			RegisterMaskField("password", MaskTypeFilled)
+			RegisterMaskField("secret", MaskTypeFilled)
+			RegisterMaskField("card", MaskTypeFilled)
+			RegisterMaskField("partial", MaskTypePartial+"1")
+			RegisterMaskField("age", MaskTypeZero)
+			RegisterMaskField("score", MaskTypeZero)
+			got, err := MaskJSON([]byte(tt.input))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	t.Run(newMaskerTestCase("random number"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskField("age", MaskTypeRandom+"10")
+		got, err := m.MaskJSON([]byte(`{"age": 20}`))
+		assert.NoError(t, err)
+		assert.Regexp(t, `^\{"age": [0-9]\}$`, string(got))
+	})
+
+	t.Run(newMaskerTestCase("unmatched"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskField("active", MaskTypeFilled)
+		m.RegisterMaskIntFunc("int", func(arg string, value int) (int, error) {
+			return 0, nil
+		})
+		m.RegisterMaskField("count", "int")
+		input := []byte(`{"active": true, "count": 1.5, "name": null}`)
+
+		got, err := m.MaskJSON(input)
+		assert.NoError(t, err)
+		assert.Equal(t, string(input), string(got))
+
+		m.SetUnmatchedPolicy(UnmatchedZero)
+		got, err = m.MaskJSON(input)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"active": false, "count": 0, "name": null}`, string(got))
+
+		m.SetUnmatchedPolicy(UnmatchedFail)
+		_, err = m.MaskJSON(input)
+		assert.ErrorIs(t, err, ErrIncompatibleMaskType)
+	})
+
+	t.Run(newMaskerTestCase("error of mask function"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskStringFunc(MaskTypeFilled, func(arg, value string) (string, error) {
+			return "", errors.New("fail")
+		})
+		m.RegisterMaskField("password", MaskTypeFilled)
+		_, err := m.MaskJSON([]byte(`{"password": "Usagi"}`))
+		assert.EqualError(t, err, "fail")
+	})
+
+	syntaxErrors := []string{
+		``,
+		` `,
+		`{`,
+		`{"a" 1}`,
+		`{"a": 1,}`,
+		`{a: 1}`,
+		`[1 2]`,
+		`[1,`,
+		`"Usagi`,
+		"\"Usa\ngi\"",
+		`"\x"`,
+		`"\u12"`,
+		`01`,
+		`1.`,
+		`tru`,
+		`nul`,
+		`{} {}`,
+		`{"password": "Usagi"`,
+		strings.Repeat("[", maxJSONDepth+2) + strings.Repeat("]", maxJSONDepth+2),
+	}
+	for _, input := range syntaxErrors {
+		name := input
+		if len(name) > 16 {
+			name = name[:16]
+		}
+		t.Run(newMaskerTestCase("syntax error "+name), func(t *testing.T) {
+			m := newMasker()
+			m.RegisterMaskField("password", MaskTypeFilled)
+			_, err := m.MaskJSON([]byte(input))
+			var syntaxErr *JSONSyntaxError
+			assert.ErrorAs(t, err, &syntaxErr)
+		})
+	}
+}
+
+func TestMaskJSONStream(t *testing.T) {
+	input := "{\"password\": \"Usagi\"}\n{\"password\": \"Chiikawa\"}\n[\"Hachiware\"] 1\n"
+	want := "{\"password\": \"*****\"}\n{\"password\": \"********\"}\n[\"Hachiware\"] 1\n"
+
+	t.Run(defaultTestCase("stream"), func(t *testing.T) {
+		defer cleanup(t)
+		RegisterMaskField("password", MaskTypeFilled)
+		var buf bytes.Buffer
+		assert.NoError(t, MaskJSONStream(&buf, strings.NewReader(input)))
+		assert.Equal(t, want, buf.String())
+	})
+	t.Run(newMaskerTestCase("stream"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskField("password", MaskTypeFilled)
+		var buf bytes.Buffer
+		assert.NoError(t, m.MaskJSONStream(&buf, strings.NewReader(input)))
+		assert.Equal(t, want, buf.String())
+	})
+	t.Run(newMaskerTestCase("empty"), func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, newMasker().MaskJSONStream(&buf, strings.NewReader("")))
+		assert.Empty(t, buf.String())
+	})
+	t.Run(newMaskerTestCase("values before syntax error"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskField("password", MaskTypeFilled)
+		var buf bytes.Buffer
+		err := m.MaskJSONStream(&buf, strings.NewReader(`{"password": "Usagi"} {"password": `))
+		var syntaxErr *JSONSyntaxError
+		assert.ErrorAs(t, err, &syntaxErr)
+		assert.Equal(t, `{"password": "*****"}`, buf.String())
+	})
+}
+
+func TestRegisterMaskJSONPath(t *testing.T) {
+	input := `{"user": {"name": "Usagi", "tokens": ["Chiikawa", "Hachiware"], "address": {"name": "Momonga"}}, "items": [{"name": "Kurimanju"}, {"name": "Rakko"}], "name": "Shisa"}`
+
+	tests := map[string]struct {
+		paths map[string]string
+		want  string
+	}{
+		"member": {
+			paths: map[string]string{"$.user.name": MaskTypeFilled},
+			want:  `{"user": {"name": "*****", "tokens": ["Chiikawa", "Hachiware"], "address": {"name": "Momonga"}}, "items": [{"name": "Kurimanju"}, {"name": "Rakko"}], "name": "Shisa"}`,
+		},
+		"bracket": {
+			paths: map[string]string{"$['user'][\"tokens\"][1]": MaskTypeFilled},
+			want:  `{"user": {"name": "Usagi", "tokens": ["Chiikawa", "*********"], "address": {"name": "Momonga"}}, "items": [{"name": "Kurimanju"}, {"name": "Rakko"}], "name": "Shisa"}`,
+		},
+		"wildcard": {
+			paths: map[string]string{"$.items[*].name": MaskTypeFilled},
+			want:  `{"user": {"name": "Usagi", "tokens": ["Chiikawa", "Hachiware"], "address": {"name": "Momonga"}}, "items": [{"name": "*********"}, {"name": "*****"}], "name": "Shisa"}`,
+		},
+		"descent": {
+			paths: map[string]string{"$.user..name": MaskTypeFilled},
+			want:  `{"user": {"name": "*****", "tokens": ["Chiikawa", "Hachiware"], "address": {"name": "*******"}}, "items": [{"name": "Kurimanju"}, {"name": "Rakko"}], "name": "Shisa"}`,
+		},
+		"object": {
+			paths: map[string]string{"$.user.*": MaskTypeFilled},
+			want:  `{"user": {"name": "*****", "tokens": ["********", "*********"], "address": {"name": "*******"}}, "items": [{"name": "Kurimanju"}, {"name": "Rakko"}], "name": "Shisa"}`,
+		},
+		"root": {
+			paths: map[string]string{"$": MaskTypeFilled, "$.user": MaskTypeFixed},
+			want:  `{"user": {"name": "********", "tokens": ["********", "********"], "address": {"name": "********"}}, "items": [{"name": "*********"}, {"name": "*****"}], "name": "*****"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			for path, maskType := range tt.paths {
+				assert.NoError(t, m.RegisterMaskJSONPath(path, maskType))
+			}
+			got, err := m.MaskJSON([]byte(input))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	t.Run(defaultTestCase("precedence"), func(t *testing.T) {
+		defer cleanup(t)
+		RegisterMaskField("name", MaskTypeFixed)
+		assert.NoError(t, RegisterMaskJSONPath("$.user.name", MaskTypeFilled))
+		assert.NoError(t, RegisterMaskJSONPath("$..name", MaskTypeZero))
+		got, err := MaskJSON([]byte(`{"user": {"name": "Usagi"}, "name": "Chiikawa"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"user": {"name": "*****"}, "name": ""}`, string(got))
+
+		// registering the path again replaces the mask type but keeps the precedence
+		assert.NoError(t, RegisterMaskJSONPath("$..name", MaskTypeFixed))
+		got, err = MaskJSON([]byte(`{"user": {"name": "Usagi"}, "name": "Chiikawa"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"user": {"name": "*****"}, "name": "********"}`, string(got))
+	})
+
+	for _, path := range []string{"", "user", "$.", "$..", "$[", "$[-1]", "$[a]", "$.user[", "$user"} {
+		t.Run(newMaskerTestCase("invalid "+path), func(t *testing.T) {
+			assert.ErrorIs(t, newMasker().RegisterMaskJSONPath(path, MaskTypeFilled), ErrInvalidJSONPath)
+		})
+	}
+}
+
+func BenchmarkMaskJSON(b *testing.B) {
+	input := []byte(`{"id": 1, "name": "Usagi", "password": "Chiikawa", "items": [{"id": 2, "token": "Hachiware"}, {"id": 3, "token": "Momonga"}]}`)
+	m := NewMasker()
+	m.RegisterMaskStringFunc(MaskTypeFilled, m.MaskFilledString)
+	m.RegisterMaskField("password", MaskTypeFilled)
+	m.RegisterMaskField("token", MaskTypeFilled)
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		m.MaskJSON(input)
+	}
+}
@@ -1,14 +1,19 @@
 package mask
 
 import (
+	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 	"reflect"
 	"strconv"
 	"strings"
-	"sync"
 	"unicode/utf8"
 	"unsafe"
 )
@@ -18,8 +23,18 @@ func init() {
 	defaultMasker.RegisterMaskStringFunc(MaskTypeFilled, defaultMasker.MaskFilledString)
 	defaultMasker.RegisterMaskStringFunc(MaskTypeFixed, defaultMasker.MaskFixedString)
 	defaultMasker.RegisterMaskStringFunc(MaskTypeHash, defaultMasker.MaskHashString)
+	defaultMasker.RegisterMaskStringFunc(MaskTypePartial, defaultMasker.MaskPartialString)
+	defaultMasker.RegisterMaskStringFunc(MaskTypeEmail, defaultMasker.MaskEmailString)
 	defaultMasker.RegisterMaskIntFunc(MaskTypeRandom, defaultMasker.MaskRandomInt)
+	defaultMasker.RegisterMaskInt64Func(MaskTypeRandom, defaultMasker.MaskRandomInt64)
+	defaultMasker.RegisterMaskUint64Func(MaskTypeRandom, defaultMasker.MaskRandomUint64)
+	defaultMasker.RegisterMaskFloat32Func(MaskTypeRandom, defaultMasker.MaskRandomFloat32)
 	defaultMasker.RegisterMaskFloat64Func(MaskTypeRandom, defaultMasker.MaskRandomFloat64)
+	defaultMasker.RegisterMaskIntFunc(MaskTypePseudo, defaultMasker.MaskPseudoInt)
+	defaultMasker.RegisterMaskInt64Func(MaskTypePseudo, defaultMasker.MaskPseudoInt64)
+	defaultMasker.RegisterMaskUint64Func(MaskTypePseudo, defaultMasker.MaskPseudoUint64)
+	defaultMasker.RegisterMaskFloat32Func(MaskTypePseudo, defaultMasker.MaskPseudoFloat32)
+	defaultMasker.RegisterMaskFloat64Func(MaskTypePseudo, defaultMasker.MaskPseudoFloat64)
 	defaultMasker.RegisterMaskAnyFunc(MaskTypeZero, defaultMasker.MaskZero)
 }
 
@@ -30,24 +45,139 @@ const maskChar = "*"
 
 // Default tag that can be specified as a mask
 const (
-	MaskTypeFilled = "filled"
-	MaskTypeFixed  = "fixed"
-	MaskTypeRandom = "random"
-	MaskTypeHash   = "hash"
-	MaskTypeZero   = "zero"
+	MaskTypeFilled  = "filled"
+	MaskTypeFixed   = "fixed"
+	MaskTypeRandom  = "random"
+	MaskTypeHash    = "hash"
+	MaskTypeZero    = "zero"
+	MaskTypePartial = "partial"
+	MaskTypeEmail   = "email"
+	MaskTypePseudo  = "pseudo"
 )
 
 var defaultMasker *Masker
 
+// HashAlgorithm is the algorithm used by MaskHashString.
+type HashAlgorithm int
+
+const (
+	// HashSHA1 hashes with sha1 without a key. This is the default.
+	HashSHA1 HashAlgorithm = iota
+	// HashHMACSHA256 hashes with HMAC-SHA256 using HashConfig.Key.
+	HashHMACSHA256
+	// HashHMACSHA512 hashes with HMAC-SHA512 using HashConfig.Key.
+	HashHMACSHA512
+)
+
+// HashConfig configures how MaskHashString hashes a string.
+type HashConfig struct {
+	Algorithm HashAlgorithm
+	// Key is the secret key of the HMAC algorithms.
+	Key []byte
+	// Length truncates the hex encoded hash to the given number of characters. 0 means no truncation.
+	Length int
+	// Prefix is prepended to the hex encoded hash.
+	Prefix string
+}
+
+// UnmatchedPolicy decides what happens to a value whose mask tag cannot be applied,
+// because no function of the mask type is registered for the kind of the value.
+type UnmatchedPolicy int
+
+const (
+	// UnmatchedKeep copies the value without masking. This is the default.
+	UnmatchedKeep UnmatchedPolicy = iota
+	// UnmatchedFail returns an *UnmatchedTagError.
+	UnmatchedFail
+	// UnmatchedZero converts the value to its type's zero value.
+	UnmatchedZero
+)
+
+// CopyPolicy decides how a value that needs no masking is copied,
+// such as a slice of structs without any mask tag.
+type CopyPolicy int
+
+const (
+	// CopyDeep copies the value recursively, so the result never shares memory with the target. This is the default.
+	CopyDeep CopyPolicy = iota
+	// CopyShallow copies only the top level of the value.
+	// A pointer, slice or map is copied to a new one, and the values inside are shared with the target.
+	CopyShallow
+	// CopyShare reuses the value of the target as it is.
+	CopyShare
+)
+
+// StructTagPolicy decides what happens to a mask tag on a struct field of a struct type,
+// such as `mask:"filled"` on a nested struct or a pointer to a struct,
+// when no function of the mask type is registered for the struct type.
+type StructTagPolicy int
+
+const (
+	// StructTagIgnore applies the UnmatchedPolicy to the struct. This is the default.
+	StructTagIgnore StructTagPolicy = iota
+	// StructTagPropagate applies the tag to the exported fields of the struct that have no tag of their own,
+	// as a tag on a slice or a map is applied to its elements.
+	// The tags of the fields, including the tags registered for the field names and the types, take precedence.
+	// A struct without exported fields, such as time.Time, is handled by the UnmatchedPolicy.
+	StructTagPropagate
+	// StructTagError returns an *UnmatchedTagError.
+	StructTagError
+)
+
+// BytesPolicy decides how a mask tag is applied to a []byte, a json.RawMessage or a [N]byte.
+type BytesPolicy int
+
+const (
+	// BytesAsString masks the bytes as a string with the functions for string. This is the default.
+	// The bytes are masked as elements if no function for string is registered for the mask type.
+	BytesAsString BytesPolicy = iota
+	// BytesAsElements masks each byte with the functions for the uint kinds.
+	BytesAsElements
+	// BytesZero fills the bytes with zeros regardless of the mask type.
+	BytesZero
+)
+
+// Rand is a source of random numbers used by the random mask functions.
+// *rand.Rand satisfies this interface.
+type Rand interface {
+	Intn(n int) int
+	Int63n(n int64) int64
+	Float64() float64
+}
+
+// globalRand is a Rand that uses the top-level functions of math/rand.
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int       { return rand.Intn(n) }
+func (globalRand) Int63n(n int64) int64 { return rand.Int63n(n) }
+func (globalRand) Float64() float64     { return rand.Float64() }
+
 // Function type that must be satisfied to add a custom mask
 type (
 	MaskStringFunc  func(arg string, value string) (string, error)
 	MaskUintFunc    func(arg string, value uint) (uint, error)
+	MaskUint64Func  func(arg string, value uint64) (uint64, error)
 	MaskIntFunc     func(arg string, value int) (int, error)
+	MaskInt64Func   func(arg string, value int64) (int64, error)
+	MaskFloat32Func func(arg string, value float32) (float32, error)
 	MaskFloat64Func func(arg string, value float64) (float64, error)
 	MaskAnyFunc     func(arg string, value any) (any, error)
 )
 
+// Maskable is implemented by types that supply their own masked representation,
+// such as types with unexported state that reflection cannot mask.
+// Masker checks it before masking a value by reflection, like json.Marshaler for encoding/json.
+//
+// MaskWith returns a masked copy of the receiver, which must have the same type as the receiver.
+// The tag is the mask tag applied to the value, or an empty string if there is none.
+// The masker can be used to mask the values inside, e.g. m.String(tag, v.name).
+// A nil pointer is not passed to MaskWith.
+type Maskable interface {
+	MaskWith(m *Masker, tag string) (any, error)
+}
+
+var maskableType = reflect.TypeOf((*Maskable)(nil)).Elem()
+
 // Mask returns an object with the mask applied to any given object.
 // The function's argument can accept any type, including pointer, map, and slice types, in addition to struct.
 // from default masker.
@@ -61,6 +191,18 @@ func Mask[T any](target T) (ret T, err error) {
 	return v.(T), nil
 }
 
+// MaskField returns the value masked as the value of a struct field or a map key named fieldName
+// from default masker.
+func MaskField(fieldName string, value any) (any, error) {
+	return defaultMasker.MaskField(fieldName, value)
+}
+
+// SetFieldNameTag makes the names registered by RegisterMaskField match the names of struct fields in the tag
+// from default masker.
+func SetFieldNameTag(s string) {
+	defaultMasker.SetFieldNameTag(s)
+}
+
 // SetMaskChar changes the character used for masking
 // from default masker.
 func SetMaskChar(s string) {
@@ -73,6 +215,49 @@ func MaskChar() string {
 	return defaultMasker.MaskChar()
 }
 
+// SetHashConfig changes the algorithm, key and output format used by MaskHashString
+// from default masker.
+func SetHashConfig(c HashConfig) {
+	defaultMasker.SetHashConfig(c)
+}
+
+// SetPseudoKey sets the secret key used by MaskPseudoInt, MaskPseudoInt64, MaskPseudoUint64,
+// MaskPseudoFloat32 and MaskPseudoFloat64
+// from default masker.
+func SetPseudoKey(key []byte) {
+	defaultMasker.SetPseudoKey(key)
+}
+
+// SetRand changes the source of random numbers used by the random mask functions
+// from default masker.
+func SetRand(r Rand) {
+	defaultMasker.SetRand(r)
+}
+
+// SetUnmatchedPolicy changes what happens to a value whose mask tag cannot be applied
+// from default masker.
+func SetUnmatchedPolicy(p UnmatchedPolicy) {
+	defaultMasker.SetUnmatchedPolicy(p)
+}
+
+// SetCopyPolicy changes how a value that needs no masking is copied
+// from default masker.
+func SetCopyPolicy(p CopyPolicy) {
+	defaultMasker.SetCopyPolicy(p)
+}
+
+// SetStructTagPolicy changes what happens to a mask tag on a struct
+// from default masker.
+func SetStructTagPolicy(p StructTagPolicy) {
+	defaultMasker.SetStructTagPolicy(p)
+}
+
+// SetBytesPolicy changes how a mask tag is applied to a []byte, a json.RawMessage or a [N]byte
+// from default masker.
+func SetBytesPolicy(p BytesPolicy) {
+	defaultMasker.SetBytesPolicy(p)
+}
+
 // RegisterMaskField allows you to register a mask tag to be applied to the value of a struct field or map key that matches the fieldName.
 // If a mask tag is set on the struct field, it will take precedence.
 // from default masker.
@@ -80,6 +265,18 @@ func RegisterMaskField(fieldName, maskType string) {
 	defaultMasker.RegisterMaskField(fieldName, maskType)
 }
 
+// RegisterMaskType allows you to register a mask tag to be applied to every value of the type rt
+// from default masker.
+func RegisterMaskType(rt reflect.Type, maskType string) {
+	defaultMasker.RegisterMaskType(rt, maskType)
+}
+
+// RegisterMaskWrapper registers a struct type that wraps a value, like sql.NullString or a generic Optional[T]
+// from default masker.
+func RegisterMaskWrapper(rt reflect.Type, valueField, validField string) {
+	defaultMasker.RegisterMaskWrapper(rt, valueField, validField)
+}
+
 // RegisterMaskStringFunc registers a masking function for string values.
 // The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
 // from default masker.
@@ -94,6 +291,13 @@ func RegisterMaskIntFunc(maskType string, maskFunc MaskIntFunc) {
 	defaultMasker.RegisterMaskIntFunc(maskType, maskFunc)
 }
 
+// RegisterMaskInt64Func registers a masking function for int64 values.
+// The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
+// from default masker.
+func RegisterMaskInt64Func(maskType string, maskFunc MaskInt64Func) {
+	defaultMasker.RegisterMaskInt64Func(maskType, maskFunc)
+}
+
 // RegisterMaskUintFunc registers a masking function for uint values.
 // The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
 // from default masker.
@@ -101,6 +305,20 @@ func RegisterMaskUintFunc(maskType string, maskFunc MaskUintFunc) {
 	defaultMasker.RegisterMaskUintFunc(maskType, maskFunc)
 }
 
+// RegisterMaskUint64Func registers a masking function for uint64 values.
+// The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
+// from default masker.
+func RegisterMaskUint64Func(maskType string, maskFunc MaskUint64Func) {
+	defaultMasker.RegisterMaskUint64Func(maskType, maskFunc)
+}
+
+// RegisterMaskFloat32Func registers a masking function for float32 values.
+// The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
+// from default masker.
+func RegisterMaskFloat32Func(maskType string, maskFunc MaskFloat32Func) {
+	defaultMasker.RegisterMaskFloat32Func(maskType, maskFunc)
+}
+
 // RegisterMaskFloat64Func registers a masking function for float64 values.
 // The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
 // from default masker.
@@ -115,6 +333,12 @@ func RegisterMaskAnyFunc(maskType string, maskFunc MaskAnyFunc) {
 	defaultMasker.RegisterMaskAnyFunc(maskType, maskFunc)
 }
 
+// RegisterMaskTypeFunc registers a masking function for the values of the type rt
+// from default masker.
+func RegisterMaskTypeFunc(rt reflect.Type, maskType string, maskFunc MaskAnyFunc) {
+	defaultMasker.RegisterMaskTypeFunc(rt, maskType, maskFunc)
+}
+
 // String masks the given argument string
 // from default masker.
 func String(tag, value string) (string, error) {
@@ -127,40 +351,34 @@ func Int(tag string, value int) (int, error) {
 	return defaultMasker.Int(tag, value)
 }
 
+// Int64 masks the given argument int64
+// from default masker.
+func Int64(tag string, value int64) (int64, error) {
+	return defaultMasker.Int64(tag, value)
+}
+
 // Uint masks the given argument int
 // from default masker.
 func Uint(tag string, value uint) (uint, error) {
 	return defaultMasker.Uint(tag, value)
 }
 
-// Float64 masks the given argument float64
+// Uint64 masks the given argument uint64
 // from default masker.
-func Float64(tag string, value float64) (float64, error) {
-	return defaultMasker.Float64(tag, value)
+func Uint64(tag string, value uint64) (uint64, error) {
+	return defaultMasker.Uint64(tag, value)
 }
 
-// structType stores the type information of a structure when caching is enabled
-type structType struct {
-	value        reflect.Value
-	structFields []reflect.StructField
-}
-
-type typeToStructCache struct {
-	m sync.RWMutex
-	v map[reflect.Type]structType
-}
-
-func (c *typeToStructCache) get(rt reflect.Type) (structType, bool) {
-	defer c.m.RUnlock()
-	c.m.RLock()
-	st, ok := c.v[rt]
-	return st, ok
+// Float32 masks the given argument float32
+// from default masker.
+func Float32(tag string, value float32) (float32, error) {
+	return defaultMasker.Float32(tag, value)
 }
 
-func (c *typeToStructCache) set(rt reflect.Type, st structType) {
-	defer c.m.Unlock()
-	c.m.Lock()
-	c.v[rt] = st
+// Float64 masks the given argument float64
+// from default masker.
+func Float64(tag string, value float64) (float64, error) {
+	return defaultMasker.Float64(tag, value)
 }
 
 // Masker is a struct that defines the masking process.
@@ -168,20 +386,34 @@ type Masker struct {
 	cache             bool
 	typeToStructCache *typeToStructCache
 	tagName           string
+	fieldNameTag      string
 	maskChar          string
+	hashConfig        HashConfig
+	pseudoKey         []byte
+	rand              Rand
+	unmatchedPolicy   UnmatchedPolicy
+	copyPolicy        CopyPolicy
+	structTagPolicy   StructTagPolicy
+	bytesPolicy       BytesPolicy
 
 	maskFieldMap map[string]string
-
-	maskStringFuncKeys  []string
-	maskStringFuncMap   map[string]MaskStringFunc
-	maskUintFuncKeys    []string
-	maskUintFuncMap     map[string]MaskUintFunc
-	maskIntFuncKeys     []string
-	maskIntFuncMap      map[string]MaskIntFunc
-	maskFloat64FuncKeys []string
-	maskFloat64FuncMap  map[string]MaskFloat64Func
-	maskAnyFuncKeys     []string
-	maskAnyFuncMap      map[string]MaskAnyFunc
+	fieldRules   fieldRules
+	maskTypeMap  map[reflect.Type]string
+	// maskWrappers holds the wrapper types by the names from genericTypeName.
+	maskWrappers map[string]wrapperRule
+	// jsonPaths holds the paths registered by RegisterMaskJSONPath in the order of registration.
+	jsonPaths []jsonPathRule
+
+	maskStringFuncs  *funcRegistry[MaskStringFunc]
+	maskUintFuncs    *funcRegistry[MaskUintFunc]
+	maskUint64Funcs  *funcRegistry[MaskUint64Func]
+	maskIntFuncs     *funcRegistry[MaskIntFunc]
+	maskInt64Funcs   *funcRegistry[MaskInt64Func]
+	maskFloat32Funcs *funcRegistry[MaskFloat32Func]
+	maskFloat64Funcs *funcRegistry[MaskFloat64Func]
+	maskAnyFuncs     *funcRegistry[MaskAnyFunc]
+	// maskTypeFuncs holds the functions for the values of a specific type.
+	maskTypeFuncs map[reflect.Type]*funcRegistry[MaskAnyFunc]
 }
 
 // NewMasker initializes a Masker.
@@ -189,24 +421,28 @@ func NewMasker() *Masker {
 	m := &Masker{
 		tagName:  TagName,
 		maskChar: maskChar,
+		rand:     globalRand{},
 
 		cache: true,
 		typeToStructCache: &typeToStructCache{
-			v: make(map[reflect.Type]structType),
+			v:         make(map[reflect.Type]structPlan),
+			needsMask: make(map[reflect.Type]bool),
 		},
 		maskFieldMap: make(map[string]string),
-
-		maskStringFuncKeys:  make([]string, 0, 10),
-		maskStringFuncMap:   make(map[string]MaskStringFunc),
-		maskUintFuncKeys:    make([]string, 0, 10),
-		maskUintFuncMap:     make(map[string]MaskUintFunc),
-		maskIntFuncKeys:     make([]string, 0, 10),
-		maskIntFuncMap:      make(map[string]MaskIntFunc),
-		maskFloat64FuncKeys: make([]string, 0, 10),
-		maskFloat64FuncMap:  make(map[string]MaskFloat64Func),
-		maskAnyFuncKeys:     make([]string, 0, 10),
-		maskAnyFuncMap:      make(map[string]MaskAnyFunc),
+		maskTypeMap:  make(map[reflect.Type]string),
+		maskWrappers: make(map[string]wrapperRule),
+
+		maskStringFuncs:  newFuncRegistry[MaskStringFunc](),
+		maskUintFuncs:    newFuncRegistry[MaskUintFunc](),
+		maskUint64Funcs:  newFuncRegistry[MaskUint64Func](),
+		maskIntFuncs:     newFuncRegistry[MaskIntFunc](),
+		maskInt64Funcs:   newFuncRegistry[MaskInt64Func](),
+		maskFloat32Funcs: newFuncRegistry[MaskFloat32Func](),
+		maskFloat64Funcs: newFuncRegistry[MaskFloat64Func](),
+		maskAnyFuncs:     newFuncRegistry[MaskAnyFunc](),
+		maskTypeFuncs:    make(map[reflect.Type]*funcRegistry[MaskAnyFunc]),
 	}
+	m.registerSQLWrappers()
 
 	return m
 }
@@ -215,14 +451,83 @@ func NewMasker() *Masker {
 func (m *Masker) SetTagName(s string) {
 	if s != "" {
 		m.tagName = s
+		m.typeToStructCache.reset()
 	}
 }
 
+// SetFieldNameTag makes the names registered by RegisterMaskField match the name of a struct field in the tag with the name,
+// such as "json", as well as the name of the field in Go.
+// The name is the part of the tag value before the first comma, as in encoding/json,
+// so that a rule for a wire name like "card_number" covers both structs and maps decoded from JSON.
+// The names registered by RegisterMaskField are checked for both names before the field name rules such as RegisterMaskFieldFold,
+// and the name of the field in Go takes precedence at each step. An empty name matches only the names in Go. This is the default.
+func (m *Masker) SetFieldNameTag(s string) {
+	m.fieldNameTag = s
+	m.typeToStructCache.reset()
+}
+
 // SetMaskChar changes the character used for masking
 func (m *Masker) SetMaskChar(s string) {
 	m.maskChar = s
 }
 
+// SetHashConfig changes the algorithm, key and output format used by MaskHashString.
+func (m *Masker) SetHashConfig(c HashConfig) {
+	c.Key = append([]byte(nil), c.Key...)
+	m.hashConfig = c
+}
+
+// SetPseudoKey sets the secret key used by MaskPseudoInt, MaskPseudoInt64, MaskPseudoUint64,
+// MaskPseudoFloat32 and MaskPseudoFloat64.
+func (m *Masker) SetPseudoKey(key []byte) {
+	m.pseudoKey = append([]byte(nil), key...)
+}
+
+// SetRand changes the source of random numbers used by the random mask functions.
+// If nil is given, the top-level functions of math/rand are used.
+// Note that *rand.Rand is not safe for concurrent use.
+func (m *Masker) SetRand(r Rand) {
+	if r == nil {
+		r = globalRand{}
+	}
+	m.rand = r
+}
+
+// SetUnmatchedPolicy changes what happens to a value whose mask tag cannot be applied,
+// such as `mask:"filled"` on a bool or a time.Time, or a tag with a mask type that is not registered.
+// By default the value is copied without masking.
+// UnmatchedFail and UnmatchedZero make sure that a missing registration never leaks the value.
+func (m *Masker) SetUnmatchedPolicy(p UnmatchedPolicy) {
+	m.unmatchedPolicy = p
+}
+
+// SetCopyPolicy changes how a value that needs no masking is copied.
+// A value needs no masking when its type contains no field with a mask tag or a tag registered for the field name.
+// Values of interface types are judged by their dynamic types.
+// CopyShallow and CopyShare make masking large values with few masked fields faster,
+// but the result shares memory with the target.
+func (m *Masker) SetCopyPolicy(p CopyPolicy) {
+	m.copyPolicy = p
+	m.typeToStructCache.reset()
+}
+
+// SetStructTagPolicy changes what happens to a mask tag on a struct,
+// such as `mask:"filled"` on a field of a nested struct type or a pointer to it.
+// By default the tag is handled by the UnmatchedPolicy, so the fields of the struct are not masked by the tag.
+// StructTagPropagate masks the fields with the tag, and StructTagError rejects the tag.
+// The functions registered for the struct type and the wrapper types are applied regardless of the policy.
+func (m *Masker) SetStructTagPolicy(p StructTagPolicy) {
+	m.structTagPolicy = p
+}
+
+// SetBytesPolicy changes how a mask tag is applied to a []byte, a json.RawMessage or a [N]byte.
+// By default the bytes are masked as a string, so `mask:"filled"` on a []byte masks the bytes like a string.
+// The result of a string function is truncated or padded with zeros to the length of a [N]byte.
+// A json.RawMessage is masked to a JSON string, or to nil with BytesZero, to keep it valid JSON.
+func (m *Masker) SetBytesPolicy(p BytesPolicy) {
+	m.bytesPolicy = p
+}
+
 // Cache can be toggled to cache the type information of the struct.
 // default true
 func (m *Masker) Cache(enable bool) {
@@ -238,71 +543,154 @@ func (m *Masker) getTag(tag, key string) string {
 	if tag != "" {
 		return tag
 	}
-	return m.maskFieldMap[key]
+	if tag := m.maskFieldMap[key]; tag != "" {
+		return tag
+	}
+	return m.lookupFieldRules(key)
+}
+
+// getFieldTag returns the mask tag of the struct field,
+// or the tag registered for the name of the field or its name in the field name tag.
+// The exact names registered by RegisterMaskField are checked for both names before the field name rules,
+// and the name in Go takes precedence over the name in the field name tag at each step.
+func (m *Masker) getFieldTag(field reflect.StructField) string {
+	if tag := field.Tag.Get(m.tagName); tag != "" {
+		return tag
+	}
+	var wireName string
+	if m.fieldNameTag != "" {
+		// "-" means that the field has no name on the wire
+		if value := field.Tag.Get(m.fieldNameTag); value != "-" {
+			wireName, _, _ = strings.Cut(value, ",")
+		}
+	}
+
+	if tag := m.maskFieldMap[field.Name]; tag != "" {
+		return tag
+	}
+	if wireName != "" {
+		if tag := m.maskFieldMap[wireName]; tag != "" {
+			return tag
+		}
+	}
+	if tag := m.lookupFieldRules(field.Name); tag != "" {
+		return tag
+	}
+	if wireName != "" {
+		return m.lookupFieldRules(wireName)
+	}
+	return ""
+}
+
+// getTypeTag returns the tag registered for the type rt.
+func (m *Masker) getTypeTag(rt reflect.Type) string {
+	if len(m.maskTypeMap) == 0 {
+		return ""
+	}
+	return m.maskTypeMap[rt]
 }
 
 // RegisterMaskStringFunc registers a masking function for string values.
 // The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
 func (m *Masker) RegisterMaskStringFunc(maskType string, maskFunc MaskStringFunc) {
-	if _, ok := m.maskStringFuncMap[maskType]; !ok {
-		m.maskStringFuncKeys = append(m.maskStringFuncKeys, maskType)
-	}
-	m.maskStringFuncMap[maskType] = maskFunc
+	m.maskStringFuncs.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
 }
 
 // RegisterMaskUintFunc registers a masking function for uint values.
 // The function will be applied when the uint slice set in the first argument is assigned as a tag to a field in the structure.
+// If the masked value does not fit in the type of the value, such as uint8, an OverflowError is returned.
 func (m *Masker) RegisterMaskUintFunc(maskType string, maskFunc MaskUintFunc) {
-	if _, ok := m.maskUintFuncMap[maskType]; !ok {
-		m.maskUintFuncKeys = append(m.maskUintFuncKeys, maskType)
-	}
-	m.maskUintFuncMap[maskType] = maskFunc
+	m.maskUintFuncs.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
+}
+
+// RegisterMaskUint64Func registers a masking function for uint64 values.
+// The function is applied to uint8, uint16, uint32 and uint64 values in preference to the function for uint values.
+// If the masked value does not fit in the type of the value, an OverflowError is returned.
+func (m *Masker) RegisterMaskUint64Func(maskType string, maskFunc MaskUint64Func) {
+	m.maskUint64Funcs.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
 }
 
 // RegisterMaskIntFunc registers a masking function for int values.
 // The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
+// If the masked value does not fit in the type of the value, such as int8, an OverflowError is returned.
 func (m *Masker) RegisterMaskIntFunc(maskType string, maskFunc MaskIntFunc) {
-	if _, ok := m.maskIntFuncMap[maskType]; !ok {
-		m.maskIntFuncKeys = append(m.maskIntFuncKeys, maskType)
-	}
-	m.maskIntFuncMap[maskType] = maskFunc
+	m.maskIntFuncs.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
+}
+
+// RegisterMaskInt64Func registers a masking function for int64 values.
+// The function is applied to int8, int16, int32 and int64 values in preference to the function for int values.
+// If the masked value does not fit in the type of the value, an OverflowError is returned.
+func (m *Masker) RegisterMaskInt64Func(maskType string, maskFunc MaskInt64Func) {
+	m.maskInt64Funcs.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
+}
+
+// RegisterMaskFloat32Func registers a masking function for float32 values.
+// The function is applied to float32 values in preference to the function for float64 values.
+func (m *Masker) RegisterMaskFloat32Func(maskType string, maskFunc MaskFloat32Func) {
+	m.maskFloat32Funcs.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
 }
 
 // RegisterMaskFloat64Func registers a masking function for float64 values.
 // The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
+// If the masked value does not fit in the type of the value, such as float32, an OverflowError is returned.
 func (m *Masker) RegisterMaskFloat64Func(maskType string, maskFunc MaskFloat64Func) {
-	if _, ok := m.maskFloat64FuncMap[maskType]; !ok {
-		m.maskFloat64FuncKeys = append(m.maskFloat64FuncKeys, maskType)
-	}
-	m.maskFloat64FuncMap[maskType] = maskFunc
+	m.maskFloat64Funcs.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
 }
 
 // RegisterMaskAnyFunc registers a masking function that can be applied to any type.
 // The function will be applied when the string set in the first argument is assigned as a tag to a field in the structure.
 func (m *Masker) RegisterMaskAnyFunc(maskType string, maskFunc MaskAnyFunc) {
-	if _, ok := m.maskAnyFuncMap[maskType]; !ok {
-		m.maskAnyFuncKeys = append(m.maskAnyFuncKeys, maskType)
+	m.maskAnyFuncs.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
+}
+
+// RegisterMaskTypeFunc registers a masking function for the values of the type rt,
+// such as a named type like `type Email string`, time.Time or net.IP.
+// The function is applied when the mask type is assigned as a tag to a value of exactly the type rt,
+// in preference to the functions for the kind of the value and the functions for any type.
+// The value passed to the function has the type rt, and the function must return a value of the type rt.
+func (m *Masker) RegisterMaskTypeFunc(rt reflect.Type, maskType string, maskFunc MaskAnyFunc) {
+	r, ok := m.maskTypeFuncs[rt]
+	if !ok {
+		r = newFuncRegistry[MaskAnyFunc]()
+		m.maskTypeFuncs[rt] = r
 	}
-	m.maskAnyFuncMap[maskType] = maskFunc
+	r.register(maskType, maskFunc)
+	m.typeToStructCache.reset()
 }
 
 // RegisterMaskField allows you to register a mask tag to be applied to the value of a struct field or map key that matches the fieldName.
 // If a mask tag is set on the struct field, it will take precedence.
 func (m *Masker) RegisterMaskField(fieldName, maskType string) {
 	m.maskFieldMap[fieldName] = maskType
+	m.typeToStructCache.reset()
+}
+
+// RegisterMaskType allows you to register a mask tag to be applied to every value of the type rt,
+// such as `type Password string`, wherever the value is and whether the field has a tag or not.
+// If a mask tag is set on the struct field or registered for the field name, it will take precedence.
+func (m *Masker) RegisterMaskType(rt reflect.Type, maskType string) {
+	m.maskTypeMap[rt] = maskType
+	m.typeToStructCache.reset()
 }
 
 // String masks the given argument string
 func (m *Masker) String(tag, value string) (string, error) {
 	if tag != "" {
-		for _, mt := range m.maskStringFuncKeys {
-			if strings.HasPrefix(tag, mt) {
-				return m.maskStringFuncMap[mt](tag[len(mt):], value)
-			}
+		if f, arg, ok := m.maskStringFuncs.lookup(tag); ok {
+			return f(arg, value)
 		}
 		if ok, v, err := m.maskAny(tag, value); ok {
 			return v.(string), err
 		}
+		return unmatched(m, tag, value)
 	}
 
 	return value, nil
@@ -311,14 +699,28 @@ func (m *Masker) String(tag, value string) (string, error) {
 // Uint masks the given argument uint
 func (m *Masker) Uint(tag string, value uint) (uint, error) {
 	if tag != "" {
-		for _, mt := range m.maskUintFuncKeys {
-			if strings.HasPrefix(tag, mt) {
-				return m.maskUintFuncMap[mt](tag[len(mt):], value)
-			}
+		if f, arg, ok := m.maskUintFuncs.lookup(tag); ok {
+			return f(arg, value)
 		}
 		if ok, v, err := m.maskAny(tag, value); ok {
 			return v.(uint), err
 		}
+		return unmatched(m, tag, value)
+	}
+
+	return value, nil
+}
+
+// Uint64 masks the given argument uint64
+func (m *Masker) Uint64(tag string, value uint64) (uint64, error) {
+	if tag != "" {
+		if f, arg, ok := m.maskUint64Funcs.lookup(tag); ok {
+			return f(arg, value)
+		}
+		if ok, v, err := m.maskAny(tag, value); ok {
+			return v.(uint64), err
+		}
+		return unmatched(m, tag, value)
 	}
 
 	return value, nil
@@ -327,14 +729,43 @@ func (m *Masker) Uint(tag string, value uint) (uint, error) {
 // Int masks the given argument int
 func (m *Masker) Int(tag string, value int) (int, error) {
 	if tag != "" {
-		for _, mt := range m.maskIntFuncKeys {
-			if strings.HasPrefix(tag, mt) {
-				return m.maskIntFuncMap[mt](tag[len(mt):], value)
-			}
+		if f, arg, ok := m.maskIntFuncs.lookup(tag); ok {
+			return f(arg, value)
 		}
 		if ok, v, err := m.maskAny(tag, value); ok {
 			return v.(int), err
 		}
+		return unmatched(m, tag, value)
+	}
+
+	return value, nil
+}
+
+// Int64 masks the given argument int64
+func (m *Masker) Int64(tag string, value int64) (int64, error) {
+	if tag != "" {
+		if f, arg, ok := m.maskInt64Funcs.lookup(tag); ok {
+			return f(arg, value)
+		}
+		if ok, v, err := m.maskAny(tag, value); ok {
+			return v.(int64), err
+		}
+		return unmatched(m, tag, value)
+	}
+
+	return value, nil
+}
+
+// Float32 masks the given argument float32
+func (m *Masker) Float32(tag string, value float32) (float32, error) {
+	if tag != "" {
+		if f, arg, ok := m.maskFloat32Funcs.lookup(tag); ok {
+			return f(arg, value)
+		}
+		if ok, v, err := m.maskAny(tag, value); ok {
+			return v.(float32), err
+		}
+		return unmatched(m, tag, value)
 	}
 
 	return value, nil
@@ -343,14 +774,13 @@ func (m *Masker) Int(tag string, value int) (int, error) {
 // Float64 masks the given argument float64
 func (m *Masker) Float64(tag string, value float64) (float64, error) {
 	if tag != "" {
-		for _, mt := range m.maskFloat64FuncKeys {
-			if strings.HasPrefix(tag, mt) {
-				return m.maskFloat64FuncMap[mt](tag[len(mt):], value)
-			}
+		if f, arg, ok := m.maskFloat64Funcs.lookup(tag); ok {
+			return f(arg, value)
 		}
 		if ok, v, err := m.maskAny(tag, value); ok {
 			return v.(float64), err
 		}
+		return unmatched(m, tag, value)
 	}
 
 	return value, nil
@@ -358,11 +788,9 @@ func (m *Masker) Float64(tag string, value float64) (float64, error) {
 
 func (m *Masker) maskAny(tag string, value any) (bool, any, error) {
 	if tag != "" {
-		for _, mt := range m.maskAnyFuncKeys {
-			if strings.HasPrefix(tag, mt) {
-				v, err := m.maskAnyFuncMap[mt](tag[len(mt):], value)
-				return true, v, err
-			}
+		if f, arg, ok := m.lookupAnyFunc(tag, reflect.TypeOf(value)); ok {
+			v, err := f(arg, value)
+			return true, v, err
 		}
 	}
 
@@ -371,37 +799,138 @@ func (m *Masker) maskAny(tag string, value any) (bool, any, error) {
 
 func (m *Masker) maskAnyValue(tag string, value reflect.Value, cb circuitBreaker) (bool, reflect.Value, error) {
 	if tag != "" {
-		for _, mt := range m.maskAnyFuncKeys {
-			if strings.HasPrefix(tag, mt) {
-				if isCircuitBreakerSupported(value.Kind()) {
-					if mp := cb.get(value); mp.IsValid() {
-						return true, mp, nil
-					}
-					v, err := m.maskAnyFuncMap[mt](tag[len(mt):], value.Interface())
-					if err != nil {
-						return true, reflect.Value{}, err
-					}
-					cb.set(value, reflect.ValueOf(v))
-					return true, reflect.ValueOf(v), nil
+		if f, arg, ok := m.lookupAnyFunc(tag, value.Type()); ok {
+			if isCircuitBreakerSupported(value.Kind()) {
+				if mp := cb.get(value); mp.IsValid() {
+					return true, mp, nil
 				}
-				v, err := m.maskAnyFuncMap[mt](tag[len(mt):], value.Interface())
-				return true, reflect.ValueOf(v), err
+				v, err := f(arg, value.Interface())
+				if err != nil {
+					return true, reflect.Value{}, err
+				}
+				cb.set(value, reflect.ValueOf(v))
+				return true, reflect.ValueOf(v), nil
 			}
+			v, err := f(arg, value.Interface())
+			return true, reflect.ValueOf(v), err
 		}
 	}
 
 	return false, value, nil
 }
 
-// MaskFilledString masks the string length of the value with the same length.
-// If you pass a number like "2" to arg, it masks with the length of the number.(**)
-func (m *Masker) MaskFilledString(arg, value string) (string, error) {
-	if arg != "" {
-		count, err := strconv.Atoi(arg)
-		if err != nil {
-			return "", err
+// lookupAnyFunc returns the function of the tag for a value of the type rt.
+// The functions for the type take precedence over the functions for any type.
+func (m *Masker) lookupAnyFunc(tag string, rt reflect.Type) (MaskAnyFunc, string, bool) {
+	if r, ok := m.maskTypeFuncs[rt]; ok {
+		if f, arg, ok := r.lookup(tag); ok {
+			return f, arg, true
 		}
+	}
+	return m.maskAnyFuncs.lookup(tag)
+}
+
+// hasTypeFuncs reports whether any function is registered for the values of the type rt.
+// Such values must keep their type instead of being masked as their kind.
+func (m *Masker) hasTypeFuncs(rt reflect.Type) bool {
+	_, ok := m.maskTypeFuncs[rt]
+	return ok
+}
+
+// isTypeSpecific reports whether the values of the type rt are masked in a way specific to the type.
+// Such values must be masked by Masker.mask instead of the fast paths for their kind.
+func (m *Masker) isTypeSpecific(rt reflect.Type) bool {
+	return m.hasTypeFuncs(rt) || m.getTypeTag(rt) != "" || isMaskable(rt)
+}
+
+// isMaskable reports whether the values of the type rt implement Maskable.
+// The values of an interface type are checked by their dynamic types.
+func isMaskable(rt reflect.Type) bool {
+	return rt.Kind() != reflect.Interface && rt.Implements(maskableType)
+}
+
+// intValue masks a value of the int kinds.
+func (m *Masker) intValue(tag string, rv reflect.Value) (int64, error) {
+	if f, ok := m.lookupIntFunc(tag, rv.Type()); ok {
+		return f.mask(rv)
+	}
+
+	rv, err := m.unmatched(tag, rv)
+	if err != nil {
+		return 0, err
+	}
+	return rv.Int(), nil
+}
+
+// uintValue masks a value of the uint kinds.
+func (m *Masker) uintValue(tag string, rv reflect.Value) (uint64, error) {
+	if f, ok := m.lookupUintFunc(tag, rv.Type()); ok {
+		return f.mask(rv)
+	}
+
+	rv, err := m.unmatched(tag, rv)
+	if err != nil {
+		return 0, err
+	}
+	return rv.Uint(), nil
+}
+
+// floatValue masks a value of the float kinds.
+func (m *Masker) floatValue(tag string, rv reflect.Value) (float64, error) {
+	if f, ok := m.lookupFloatFunc(tag, rv.Kind()); ok {
+		return f.mask(rv)
+	}
+
+	rv, err := m.unmatched(tag, rv)
+	if err != nil {
+		return 0, err
+	}
+	return rv.Float(), nil
+}
 
+// unmatched applies the UnmatchedPolicy to a value whose tag cannot be applied.
+func (m *Masker) unmatched(tag string, rv reflect.Value) (reflect.Value, error) {
+	switch m.unmatchedPolicy {
+	case UnmatchedFail:
+		return reflect.Value{}, m.unmatchedTagError(tag, rv.Type())
+	case UnmatchedZero:
+		return reflect.Zero(rv.Type()), nil
+	default:
+		return rv, nil
+	}
+}
+
+// unmatchedTagError returns the error for the tag that cannot be applied to a value of the type.
+func (m *Masker) unmatchedTagError(tag string, rt reflect.Type) error {
+	err := ErrIncompatibleMaskType
+	if !m.isRegisteredMaskType(tag) {
+		err = ErrUnregisteredMaskType
+	}
+	return &UnmatchedTagError{Tag: tag, Type: rt, Err: err}
+}
+
+func unmatched[T any](m *Masker, tag string, value T) (T, error) {
+	if m.unmatchedPolicy == UnmatchedKeep {
+		return value, nil
+	}
+	rv, err := m.unmatched(tag, reflect.ValueOf(value))
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return rv.Interface().(T), nil
+}
+
+// MaskFilledString masks the string length of the value with the same length.
+// If you pass a number like "2" or "(count=2)" to arg, it masks with the length of the number.(**)
+func (m *Masker) MaskFilledString(arg, value string) (string, error) {
+	args, err := parseArgs(MaskTypeFilled, arg)
+	if err != nil {
+		return "", err
+	}
+	if count, ok, err := argCount(MaskTypeFilled, args, "count", 0); err != nil {
+		return "", err
+	} else if ok {
 		return strings.Repeat(m.MaskChar(), count), nil
 	}
 
@@ -413,46 +942,398 @@ func (m *Masker) MaskFixedString(arg, value string) (string, error) {
 	return strings.Repeat(m.MaskChar(), 8), nil
 }
 
-// MaskHashString masks and hashes (sha1) a string.
+// MaskHashString masks and hashes a string.
+// By default it uses sha1, and the algorithm and key can be changed with SetHashConfig.
 func (m *Masker) MaskHashString(arg, value string) (string, error) {
-	hash := sha1.Sum(([]byte)(value))
-	return hex.EncodeToString(hash[:]), nil
+	var sum []byte
+	switch m.hashConfig.Algorithm {
+	case HashSHA1:
+		hash := sha1.Sum(([]byte)(value))
+		sum = hash[:]
+	case HashHMACSHA256, HashHMACSHA512:
+		if len(m.hashConfig.Key) == 0 {
+			return "", ErrHashKeyRequired
+		}
+		newHash := sha256.New
+		if m.hashConfig.Algorithm == HashHMACSHA512 {
+			newHash = sha512.New
+		}
+		mac := hmac.New(newHash, m.hashConfig.Key)
+		mac.Write(([]byte)(value))
+		sum = mac.Sum(nil)
+	default:
+		return "", fmt.Errorf("mask: unknown hash algorithm %d", m.hashConfig.Algorithm)
+	}
+
+	hash := hex.EncodeToString(sum)
+	if m.hashConfig.Length > 0 && m.hashConfig.Length < len(hash) {
+		hash = hash[:m.hashConfig.Length]
+	}
+	return m.hashConfig.Prefix + hash, nil
+}
+
+// MaskPartialString masks the middle of the string and keeps the leading and trailing characters.
+// For example, if you pass "2,4" to arg, it keeps the first 2 and the last 4 characters.(ab******wxyz)
+// "2" keeps only the first 2 characters and ",4" keeps only the last 4 characters.
+// If the characters to keep would cover the whole string, the whole string is masked.
+func (m *Masker) MaskPartialString(arg, value string) (string, error) {
+	first, last, err := parsePartialArg(arg)
+	if err != nil {
+		return "", err
+	}
+
+	runes := []rune(value)
+	// compared without adding first and last, which may overflow
+	if first >= len(runes) || last >= len(runes)-first {
+		return strings.Repeat(m.MaskChar(), len(runes)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(string(runes[:first]))
+	sb.WriteString(strings.Repeat(m.MaskChar(), len(runes)-first-last))
+	sb.WriteString(string(runes[len(runes)-last:]))
+	return sb.String(), nil
+}
+
+func parsePartialArg(arg string) (first, last int, err error) {
+	args, err := parseArgs(MaskTypePartial, arg)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if keep, ok, err := argCount(MaskTypePartial, args, "keep", -1); err != nil {
+		return 0, 0, err
+	} else if ok {
+		switch from, _ := args.Lookup("from"); from {
+		case "", "start":
+			return keep, 0, nil
+		case "end":
+			return 0, keep, nil
+		case "both":
+			return keep, keep, nil
+		default:
+			return 0, 0, &InvalidArgError{MaskType: MaskTypePartial, Arg: arg, Err: fmt.Errorf("unknown from %q", from)}
+		}
+	}
+
+	if args.Len() > 2 {
+		return 0, 0, &InvalidArgError{MaskType: MaskTypePartial, Arg: arg, Err: errors.New("too many arguments")}
+	}
+	first, okFirst, err := argCount(MaskTypePartial, args, "first", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	last, okLast, err := argCount(MaskTypePartial, args, "last", 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !okFirst && !okLast {
+		return 0, 0, &InvalidArgError{MaskType: MaskTypePartial, Arg: arg, Err: errors.New("the number of characters to keep is required")}
+	}
+
+	return first, last, nil
+}
+
+// MaskEmailString masks the local part of an email address and keeps the domain.(****@example.com)
+// If you pass a number like "1" or "(keep=1)" to arg, it keeps that many leading characters of the local part.(u***@example.com)
+// If you add ",domain" or "domain=true" to arg, the domain is also masked except for its top-level domain.(u***@*******.com)
+// A value that is not an email address is masked entirely.
+func (m *Masker) MaskEmailString(arg, value string) (string, error) {
+	keep, maskDomain, err := parseEmailArg(arg)
+	if err != nil {
+		return "", err
+	}
+
+	at := strings.LastIndex(value, "@")
+	if at < 0 {
+		return strings.Repeat(m.MaskChar(), utf8.RuneCountInString(value)), nil
+	}
+	local, domain := []rune(value[:at]), value[at+1:]
+
+	var sb strings.Builder
+	if keep >= len(local) {
+		keep = 0
+	}
+	sb.WriteString(string(local[:keep]))
+	sb.WriteString(strings.Repeat(m.MaskChar(), len(local)-keep))
+	sb.WriteString("@")
+	if !maskDomain {
+		sb.WriteString(domain)
+		return sb.String(), nil
+	}
+
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		if i > 0 {
+			sb.WriteString(".")
+		}
+		if i == len(labels)-1 && i > 0 {
+			sb.WriteString(label)
+			continue
+		}
+		sb.WriteString(strings.Repeat(m.MaskChar(), utf8.RuneCountInString(label)))
+	}
+	return sb.String(), nil
+}
+
+func parseEmailArg(arg string) (keep int, maskDomain bool, err error) {
+	args, err := parseArgs(MaskTypeEmail, arg)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if keep, _, err = argCount(MaskTypeEmail, args, "keep", 0); err != nil {
+		return 0, false, err
+	}
+	if v, ok := args.Lookup("domain"); ok {
+		if maskDomain, err = strconv.ParseBool(v); err != nil {
+			return 0, false, &InvalidArgError{MaskType: MaskTypeEmail, Arg: arg, Err: err}
+		}
+	}
+	for i := 1; i < args.Len(); i++ {
+		switch option, _ := args.Index(i); option {
+		case "domain":
+			maskDomain = true
+		default:
+			return 0, false, &InvalidArgError{MaskType: MaskTypeEmail, Arg: arg, Err: fmt.Errorf("unknown option %q", option)}
+		}
+	}
+
+	return keep, maskDomain, nil
 }
 
 // MaskRandomInt converts an integer (int) into a random number.
 // For example, if you pass "100" as the arg, it sets a random number in the range of 0-99.
+// If the range exceeds the int type, e.g. on 32-bit platforms, an InvalidArgError is returned.
 func (m *Masker) MaskRandomInt(arg string, value int) (int, error) {
-	n, err := strconv.Atoi(arg)
+	n, err := parseIntRangeArg(MaskTypeRandom, arg)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt {
+		return 0, &InvalidArgError{MaskType: MaskTypeRandom, Arg: arg, Err: errors.New("the range exceeds the int type")}
+	}
+
+	return int(m.randomInt64(n)), nil
+}
+
+// MaskRandomInt64 converts an integer (int64) into a random number.
+// For example, if you pass "100" as the arg, it sets a random number in the range of 0-99.
+func (m *Masker) MaskRandomInt64(arg string, value int64) (int64, error) {
+	n, err := parseIntRangeArg(MaskTypeRandom, arg)
 	if err != nil {
 		return 0, err
 	}
 
-	return rand.Intn(n), nil
+	return m.randomInt64(n), nil
+}
+
+// MaskRandomUint64 converts an unsigned integer (uint64) into a random number.
+// For example, if you pass "100" as the arg, it sets a random number in the range of 0-99.
+func (m *Masker) MaskRandomUint64(arg string, value uint64) (uint64, error) {
+	n, err := parseIntRangeArg(MaskTypeRandom, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(m.randomInt64(n)), nil
+}
+
+func (m *Masker) randomInt64(n int64) int64 {
+	// Intn keeps the same sequence as MaskRandomInt for the same source.
+	if n <= math.MaxInt32 {
+		return int64(m.rand.Intn(int(n)))
+	}
+	return m.rand.Int63n(n)
+}
+
+// MaskRandomFloat32 converts a float32 to a random number.
+// For example, if you pass "100.3" to arg, it sets a random number in the range of 0.000 to 99.999.
+func (m *Masker) MaskRandomFloat32(arg string, value float32) (float32, error) {
+	f, err := m.MaskRandomFloat64(arg, float64(value))
+	return float32(f), err
 }
 
 // MaskRandomFloat64 converts a float64 to a random number.
 // For example, if you pass "100.3" to arg, it sets a random number in the range of 0.000 to 99.999.
 func (m *Masker) MaskRandomFloat64(arg string, value float64) (float64, error) {
-	var (
-		i, d int
-		err  error
-	)
-	digits := strings.Split(arg, ".")
-	if len(digits) > 0 {
-		if i, err = strconv.Atoi(digits[0]); err != nil {
-			return 0, err
+	i, d, err := parseFloatRangeArg(MaskTypeRandom, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return scaleFloat64(m.rand.Float64(), i, d), nil
+}
+
+// MaskPseudoInt converts an integer (int) into a pseudonym number that is always the same for the same value.
+// For example, if you pass "100" as the arg, it sets a number in the range of 0-99.
+// The number is derived from the value with HMAC-SHA256 using the key set by SetPseudoKey.
+// If the range exceeds the int type, e.g. on 32-bit platforms, an InvalidArgError is returned.
+func (m *Masker) MaskPseudoInt(arg string, value int) (int, error) {
+	n, err := parseIntRangeArg(MaskTypePseudo, arg)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt {
+		return 0, &InvalidArgError{MaskType: MaskTypePseudo, Arg: arg, Err: errors.New("the range exceeds the int type")}
+	}
+	h, err := m.pseudoUint64(uint64(value))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(h % uint64(n)), nil
+}
+
+// MaskPseudoInt64 converts an integer (int64) into a pseudonym number that is always the same for the same value.
+// For example, if you pass "100" as the arg, it sets a number in the range of 0-99.
+func (m *Masker) MaskPseudoInt64(arg string, value int64) (int64, error) {
+	n, err := parseIntRangeArg(MaskTypePseudo, arg)
+	if err != nil {
+		return 0, err
+	}
+	h, err := m.pseudoUint64(uint64(value))
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(h % uint64(n)), nil
+}
+
+// MaskPseudoUint64 converts an unsigned integer (uint64) into a pseudonym number that is always the same for the same value.
+// For example, if you pass "100" as the arg, it sets a number in the range of 0-99.
+func (m *Masker) MaskPseudoUint64(arg string, value uint64) (uint64, error) {
+	n, err := parseIntRangeArg(MaskTypePseudo, arg)
+	if err != nil {
+		return 0, err
+	}
+	h, err := m.pseudoUint64(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return h % uint64(n), nil
+}
+
+// MaskPseudoFloat32 converts a float32 into a pseudonym number that is always the same for the same value.
+// For example, if you pass "100.3" to arg, it sets a number in the range of 0.000 to 99.999.
+func (m *Masker) MaskPseudoFloat32(arg string, value float32) (float32, error) {
+	f, err := m.MaskPseudoFloat64(arg, float64(value))
+	return float32(f), err
+}
+
+// MaskPseudoFloat64 converts a float64 into a pseudonym number that is always the same for the same value.
+// For example, if you pass "100.3" to arg, it sets a number in the range of 0.000 to 99.999.
+// The number is derived from the value with HMAC-SHA256 using the key set by SetPseudoKey.
+func (m *Masker) MaskPseudoFloat64(arg string, value float64) (float64, error) {
+	i, d, err := parseFloatRangeArg(MaskTypePseudo, arg)
+	if err != nil {
+		return 0, err
+	}
+	h, err := m.pseudoUint64(math.Float64bits(value))
+	if err != nil {
+		return 0, err
+	}
+
+	return scaleFloat64(float64(h>>11)/(1<<53), i, d), nil
+}
+
+func (m *Masker) pseudoUint64(value uint64) (uint64, error) {
+	if len(m.pseudoKey) == 0 {
+		return 0, ErrPseudoKeyRequired
+	}
+
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], value)
+	mac := hmac.New(sha256.New, m.pseudoKey)
+	mac.Write(b[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil)), nil
+}
+
+// parseArgs parses the argument of the mask type.
+func parseArgs(maskType, arg string) (MaskArgs, error) {
+	args, err := ParseMaskArgs(arg)
+	if err != nil {
+		// declared here so that a successful call does not allocate it
+		var argErr *InvalidArgError
+		if errors.As(err, &argErr) {
+			argErr.MaskType = maskType
 		}
 	}
+
+	return args, err
+}
+
+// argCount returns the number of characters given as the named value or the i-th positional value.
+func argCount(maskType string, args MaskArgs, name string, i int) (int, bool, error) {
+	v, ok := args.Get(name, i)
+	if !ok {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, true, &InvalidArgError{MaskType: maskType, Arg: args.Raw(), Err: err}
+	}
+	if n < 0 {
+		return 0, true, &InvalidArgError{MaskType: maskType, Arg: args.Raw(), Err: errors.New("negative number of characters")}
+	}
+
+	return n, true, nil
+}
+
+// parseIntRangeArg parses an argument like "100" or "(max=100)" into a positive upper bound.
+func parseIntRangeArg(maskType, arg string) (int64, error) {
+	args, err := parseArgs(maskType, arg)
+	if err != nil {
+		return 0, err
+	}
+	v, _ := args.Get("max", 0)
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, &InvalidArgError{MaskType: maskType, Arg: arg, Err: err}
+	}
+	if n <= 0 {
+		return 0, &InvalidArgError{MaskType: maskType, Arg: arg, Err: errors.New("the range must be positive")}
+	}
+
+	return n, nil
+}
+
+// parseFloatRangeArg parses an argument like "100.3" or "(max=100,digits=3)"
+// into the integer part and the number of decimal places.
+func parseFloatRangeArg(maskType, arg string) (i, d int, err error) {
+	args, err := parseArgs(maskType, arg)
+	if err != nil {
+		return 0, 0, err
+	}
+	v, _ := args.Get("max", 0)
+	digits := strings.Split(v, ".")
+	if len(digits) > 2 {
+		return 0, 0, &InvalidArgError{MaskType: maskType, Arg: arg, Err: errors.New("too many decimal points")}
+	}
+	if i, err = strconv.Atoi(digits[0]); err != nil {
+		return 0, 0, &InvalidArgError{MaskType: maskType, Arg: arg, Err: err}
+	}
 	if len(digits) == 2 {
 		if d, err = strconv.Atoi(digits[1]); err != nil {
-			return 0, err
+			return 0, 0, &InvalidArgError{MaskType: maskType, Arg: arg, Err: err}
 		}
 	}
+	if v, ok := args.Lookup("digits"); ok {
+		if d, err = strconv.Atoi(v); err != nil {
+			return 0, 0, &InvalidArgError{MaskType: maskType, Arg: arg, Err: err}
+		}
+	}
+
+	return i, d, nil
+}
 
+// scaleFloat64 scales f in [0, 1) to the range of 0 to i with d decimal places.
+func scaleFloat64(f float64, i, d int) float64 {
 	dd := math.Pow10(d)
-	x := float64(int(rand.Float64() * float64(i) * dd))
+	x := float64(int(f * float64(i) * dd))
 
-	return x / dd, nil
+	return x / dd
 }
 
 // MaskZero converts the value to its type's zero value.
@@ -472,6 +1353,23 @@ func (m *Masker) Mask(target any) (ret any, err error) {
 	return rv.Interface(), nil
 }
 
+// MaskField returns the value masked as the value of a struct field or a map key named fieldName.
+// The mask tag registered for the field name by RegisterMaskField is applied to the value,
+// so that values without a struct, such as the attributes of a structured log, can be masked by their names.
+// If no tag is registered for the field name, the value is masked as by Mask.
+func (m *Masker) MaskField(fieldName string, value any) (ret any, err error) {
+	if value == nil {
+		return nil, nil
+	}
+	cb := localCircuitBreaker{}
+	rv, err := m.mask(reflect.ValueOf(value), m.getTag("", fieldName), reflect.Value{}, cb)
+	if err != nil {
+		return ret, err
+	}
+
+	return rv.Interface(), nil
+}
+
 type localCircuitBreaker map[interface{}]reflect.Value
 
 func (cb localCircuitBreaker) get(rv reflect.Value) reflect.Value {
@@ -497,6 +1395,18 @@ type circuitBreaker interface {
 }
 
 func (m *Masker) mask(rv reflect.Value, tag string, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
+	if tag == "" {
+		tag = m.getTypeTag(rv.Type())
+	}
+	if isMaskable(rv.Type()) && !(rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return m.maskSelf(rv, tag, mp)
+	}
+	if tag == "" && m.copyPolicy != CopyDeep && !m.needsMask(rv.Type()) {
+		return m.copyValue(rv, mp, cb)
+	}
+	if w := m.wrapperFor(rv.Type(), tag); w != nil {
+		return m.maskWrapper(w, rv, tag, mp, cb)
+	}
 	if ok, v, err := m.maskAnyValue(tag, rv, cb); ok {
 		return v, err
 	}
@@ -507,15 +1417,16 @@ func (m *Masker) mask(rv reflect.Value, tag string, mp reflect.Value, cb circuit
 		return m.maskPtr(rv, tag, mp, cb)
 	case reflect.Struct:
 		return m.maskStruct(rv, tag, mp, cb)
-	case reflect.Array:
-		return m.maskSlice(rv, tag, mp, cb)
-	case reflect.Slice:
-		if rv.IsNil() {
+	case reflect.Array, reflect.Slice:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
 			return reflect.Zero(rv.Type()), nil
 		}
-		return m.maskSlice(rv, tag, mp, cb)
+		if ok, v, err := m.maskBytes(tag, rv, mp); ok {
+			return v, err
+		}
+		return m.maskSlice(rv, tag, valueFunc{}, mp, cb)
 	case reflect.Map:
-		return m.maskMap(rv, tag, mp, cb)
+		return m.maskMap(rv, tag, valueFunc{}, mp, cb)
 	case reflect.String:
 		return m.maskString(rv, tag, mp)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -525,6 +1436,12 @@ func (m *Masker) mask(rv reflect.Value, tag string, mp reflect.Value, cb circuit
 	case reflect.Float32, reflect.Float64:
 		return m.maskfloat(rv, tag, mp)
 	default:
+		if tag != "" {
+			var err error
+			if rv, err = m.unmatched(tag, rv); err != nil {
+				return reflect.Value{}, err
+			}
+		}
 		if mp.CanSet() {
 			mp.Set(rv)
 			return mp, nil
@@ -533,6 +1450,62 @@ func (m *Masker) mask(rv reflect.Value, tag string, mp reflect.Value, cb circuit
 	}
 }
 
+// maskSelf masks a value that implements Maskable.
+func (m *Masker) maskSelf(rv reflect.Value, tag string, mp reflect.Value) (reflect.Value, error) {
+	v, err := rv.Interface().(Maskable).MaskWith(m, tag)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	rv2 := reflect.ValueOf(v)
+	if !rv2.IsValid() {
+		rv2 = reflect.Zero(rv.Type())
+	}
+	if rv2.Type() != rv.Type() {
+		return reflect.Value{}, fmt.Errorf("mask: MaskWith of %s returned %s", rv.Type(), rv2.Type())
+	}
+	if mp.CanSet() {
+		mp.Set(rv2)
+		return mp, nil
+	}
+	return rv2, nil
+}
+
+// copyValue copies a value that needs no masking according to the CopyPolicy.
+func (m *Masker) copyValue(rv reflect.Value, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
+	rv2 := rv
+	if m.copyPolicy == CopyShallow && isCircuitBreakerSupported(rv.Kind()) {
+		switch {
+		case rv.IsNil():
+			rv2 = reflect.Zero(rv.Type())
+		case cb.get(rv).IsValid():
+			rv2 = cb.get(rv)
+		default:
+			switch rv.Kind() {
+			case reflect.Ptr:
+				rv2 = reflect.New(rv.Type().Elem())
+				rv2.Elem().Set(rv.Elem())
+			case reflect.Slice:
+				rv2 = reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+				reflect.Copy(rv2, rv)
+			case reflect.Map:
+				rv2 = reflect.MakeMapWithSize(rv.Type(), rv.Len())
+				iter := rv.MapRange()
+				for iter.Next() {
+					rv2.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+			cb.set(rv, rv2)
+		}
+	}
+
+	if mp.CanSet() {
+		mp.Set(rv2)
+		return mp, nil
+	}
+	return rv2, nil
+}
+
 func (m *Masker) maskInterface(rv reflect.Value, tag string, _ reflect.Value, cb circuitBreaker) (reflect.Value, error) {
 	if rv.IsNil() {
 		return reflect.Zero(rv.Type()), nil
@@ -567,69 +1540,57 @@ func (m *Masker) maskPtr(rv reflect.Value, tag string, _ reflect.Value, cb circu
 	return mp, nil
 }
 
-func (m *Masker) maskStruct(rv reflect.Value, _ string, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
+func (m *Masker) maskStruct(rv reflect.Value, tag string, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
+	rt := rv.Type()
+	plan := m.structPlan(rt)
+	// a tag on a struct is applied by the functions for any type, or by the StructTagPolicy
+	if tag != "" {
+		switch {
+		case m.structTagPolicy == StructTagError:
+			return reflect.Value{}, m.unmatchedTagError(tag, rt)
+		case m.structTagPolicy == StructTagPropagate && len(plan.untagged)+len(plan.fields) > 0:
+		case m.unmatchedPolicy != UnmatchedKeep:
+			return m.unmatched(tag, rv)
+		default:
+			tag = ""
+		}
+	}
 	if rv.IsZero() {
-		return reflect.Zero(rv.Type()), nil
+		return reflect.Zero(rt), nil
 	}
 
-	var (
-		rt = rv.Type()
-		st structType
-		ok bool
-	)
-	if m.cache {
-		st, ok = m.typeToStructCache.get(rt)
-		if !ok {
-			st.value = reflect.New(rt).Elem()
-			for i := 0; i < rt.NumField(); i++ {
-				st.structFields = append(st.structFields, rt.Field(i))
-			}
-			m.typeToStructCache.set(rt, st)
-		}
-		if !mp.IsValid() {
-			mp = st.value
-		}
-	} else {
-		if !mp.IsValid() {
-			mp = reflect.New(rt).Elem()
-		}
+	if !mp.IsValid() {
+		mp = reflect.New(rt).Elem()
 	}
 
-	// copy private fields
+	// copy private fields and the fields that are not masked
 	mp.Set(rv)
 
-	for i := 0; i < rt.NumField(); i++ {
-		var field reflect.StructField
-		if m.cache {
-			field = st.structFields[i]
-		} else {
-			field = rt.Field(i)
-		}
-		// skip private field
-		if !field.IsExported() {
+	for _, field := range plan.fields {
+		if tag != "" && field.tag == "" {
+			// masked with the propagated tag below
 			continue
 		}
-		tag := field.Tag.Get(m.tagName)
-		switch field.Type.Kind() {
-		case reflect.String:
-			s, err := m.String(m.getTag(tag, field.Name), rv.Field(i).String())
-			if err != nil {
-				return reflect.Value{}, err
-			}
-			mp.Field(i).SetString(s)
-		default:
-			rvf, err := m.mask(rv.Field(i), m.getTag(tag, field.Name), mp.Field(i), cb)
+		if err := m.maskField(field, rv.Field(field.index), mp.Field(field.index), cb); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if tag != "" {
+		for _, index := range plan.untagged {
+			rvf, err := m.mask(rv.Field(index), tag, mp.Field(index), cb)
 			if err != nil {
 				return reflect.Value{}, err
 			}
-			mp.Field(i).Set(rvf)
+			mp.Field(index).Set(rvf)
 		}
 	}
 
 	return mp, nil
 }
 
-func (m *Masker) maskSlice(rv reflect.Value, tag string, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
+// maskSlice masks the elements of a slice or an array.
+// If the op of elem is not opMask, the elements are masked with the function resolved for the tag in advance.
+func (m *Masker) maskSlice(rv reflect.Value, tag string, elem valueFunc, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
 	var rv2 reflect.Value
 
 	if rv.Kind() == reflect.Array {
@@ -644,9 +1605,19 @@ func (m *Masker) maskSlice(rv reflect.Value, tag string, mp reflect.Value, cb ci
 		rv2 = reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
 		cb.set(rv, rv2)
 	}
+	kind := rv.Type().Elem().Kind()
+	if m.isTypeSpecific(rv.Type().Elem()) {
+		kind = reflect.Invalid
+	}
 	for i := 0; i < rv.Len(); i++ {
 		value := rv.Index(i)
-		switch rv.Type().Elem().Kind() {
+		if elem.op != opMask {
+			if err := elem.apply(value, rv2.Index(i)); err != nil {
+				return reflect.Value{}, err
+			}
+			continue
+		}
+		switch kind {
 		case reflect.String:
 			rvf, err := m.String(tag, value.String())
 			if err != nil {
@@ -688,14 +1659,14 @@ func (m *Masker) maskSlice(rv reflect.Value, tag string, mp reflect.Value, cb ci
 	return rv2, nil
 }
 
-func (m *Masker) maskMap(rv reflect.Value, tag string, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
+func (m *Masker) maskMap(rv reflect.Value, tag string, elem valueFunc, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
 	if rv.IsNil() {
 		return reflect.Zero(rv.Type()), nil
 	}
 
 	switch rv.Type().Key().Kind() {
 	case reflect.String:
-		rv2, err := m.maskStringKeyMap(rv, tag, cb)
+		rv2, err := m.maskStringKeyMap(rv, tag, elem, cb)
 		if err != nil {
 			return reflect.Value{}, err
 		}
@@ -735,8 +1706,18 @@ func (m *Masker) maskAnyKeyMap(rv reflect.Value, tag string, cb circuitBreaker)
 	return rv2, nil
 }
 
-func (m *Masker) maskStringKeyMap(rv reflect.Value, tag string, cb circuitBreaker) (reflect.Value, error) {
-	switch rv.Type().Elem().Kind() {
+// maskStringKeyMap masks the values of a map with string keys by the tag or the tags registered for the keys.
+// If the op of elem is not opMask, the values are masked with the function resolved for the tag in advance.
+func (m *Masker) maskStringKeyMap(rv reflect.Value, tag string, elem valueFunc, cb circuitBreaker) (reflect.Value, error) {
+	kind := rv.Type().Elem().Kind()
+	if !isPredeclared(rv.Type().Elem()) || m.isTypeSpecific(rv.Type().Elem()) {
+		// the fast paths convert the map to a map of the predeclared type, which loses a named type
+		kind = reflect.Invalid
+	}
+	if elem.op != opMask && elem.op != opString {
+		kind = reflect.Invalid
+	}
+	switch kind {
 	case reflect.String:
 		if mp := cb.get(rv); mp.IsValid() {
 			return mp, nil
@@ -744,7 +1725,15 @@ func (m *Masker) maskStringKeyMap(rv reflect.Value, tag string, cb circuitBreake
 		mm := make(map[string]string, rv.Len())
 		cb.set(rv, reflect.ValueOf(mm))
 		for k, v := range rv.Convert(reflect.TypeOf(mm)).Interface().(map[string]string) {
-			rvf, err := m.String(m.getTag(tag, k), v)
+			var (
+				rvf string
+				err error
+			)
+			if elem.op == opString {
+				rvf, err = elem.stringFunc(elem.arg, v)
+			} else {
+				rvf, err = m.String(m.getTag(tag, k), v)
+			}
 			if err != nil {
 				return reflect.Value{}, err
 			}
@@ -787,9 +1776,21 @@ func (m *Masker) maskStringKeyMap(rv reflect.Value, tag string, cb circuitBreake
 		}
 		rv2 := reflect.MakeMapWithSize(rv.Type(), rv.Len())
 		cb.set(rv, rv2)
+		var elemValue reflect.Value
+		if elem.op != opMask {
+			// SetMapIndex copies the value, so a single value is reused for every element
+			elemValue = reflect.New(rv.Type().Elem()).Elem()
+		}
 		iter := rv.MapRange()
 		for iter.Next() {
 			key, value := iter.Key(), iter.Value()
+			if elem.op != opMask {
+				if err := elem.apply(value, elemValue); err != nil {
+					return reflect.Value{}, err
+				}
+				rv2.SetMapIndex(key, elemValue)
+				continue
+			}
 			rf, err := m.mask(value, m.getTag(tag, key.String()), reflect.Value{}, cb)
 			if err != nil {
 				return reflect.Value{}, err
@@ -814,16 +1815,12 @@ func (m *Masker) maskString(rv reflect.Value, tag string, mp reflect.Value) (ref
 	if err != nil {
 		return reflect.Value{}, err
 	}
-	if mp.CanSet() {
-		mp.SetString(sp)
-		return mp, nil
+	if !mp.CanSet() {
+		mp = reflect.New(rv.Type()).Elem()
 	}
+	mp.SetString(sp)
 
-	return valueOfString(sp), nil
-}
-
-func valueOfString(s string) reflect.Value {
-	return reflect.ValueOf(&s).Elem()
+	return mp, nil
 }
 
 func (m *Masker) maskInt(rv reflect.Value, tag string, mp reflect.Value) (reflect.Value, error) {
@@ -835,20 +1832,16 @@ func (m *Masker) maskInt(rv reflect.Value, tag string, mp reflect.Value) (reflec
 		return rv, nil
 	}
 
-	ip, err := m.Int(tag, int(rv.Int()))
+	ip, err := m.intValue(tag, rv)
 	if err != nil {
 		return reflect.Value{}, err
 	}
-	if mp.CanSet() {
-		mp.SetInt(int64(ip))
-		return mp, nil
-	}
-
-	if rv.Type().Kind() != reflect.Int {
-		return reflect.ValueOf(&ip).Elem().Convert(rv.Type()), nil
+	if !mp.CanSet() {
+		mp = reflect.New(rv.Type()).Elem()
 	}
+	mp.SetInt(ip)
 
-	return reflect.ValueOf(&ip).Elem(), nil
+	return mp, nil
 }
 
 func (m *Masker) maskUint(rv reflect.Value, tag string, mp reflect.Value) (reflect.Value, error) {
@@ -860,20 +1853,16 @@ func (m *Masker) maskUint(rv reflect.Value, tag string, mp reflect.Value) (refle
 		return rv, nil
 	}
 
-	ip, err := m.Uint(tag, uint(rv.Uint()))
+	ip, err := m.uintValue(tag, rv)
 	if err != nil {
 		return reflect.Value{}, err
 	}
-	if mp.CanSet() {
-		mp.SetUint(uint64(ip))
-		return mp, nil
-	}
-
-	if rv.Type().Kind() != reflect.Uint {
-		return reflect.ValueOf(&ip).Elem().Convert(rv.Type()), nil
+	if !mp.CanSet() {
+		mp = reflect.New(rv.Type()).Elem()
 	}
+	mp.SetUint(ip)
 
-	return reflect.ValueOf(&ip).Elem(), nil
+	return mp, nil
 }
 
 func (m *Masker) maskfloat(rv reflect.Value, tag string, mp reflect.Value) (reflect.Value, error) {
@@ -885,20 +1874,21 @@ func (m *Masker) maskfloat(rv reflect.Value, tag string, mp reflect.Value) (refl
 		return rv, nil
 	}
 
-	fp, err := m.Float64(tag, rv.Float())
+	fp, err := m.floatValue(tag, rv)
 	if err != nil {
 		return reflect.Value{}, err
 	}
-	if mp.CanSet() {
-		mp.SetFloat(fp)
-		return mp, nil
+	if !mp.CanSet() {
+		mp = reflect.New(rv.Type()).Elem()
 	}
+	mp.SetFloat(fp)
 
-	if rv.Type().Kind() != reflect.Float64 {
-		return reflect.ValueOf(&fp).Elem().Convert(rv.Type()), nil
-	}
+	return mp, nil
+}
 
-	return reflect.ValueOf(&fp).Elem(), nil
+// isPredeclared reports whether the type is a predeclared type like string or int.
+func isPredeclared(rt reflect.Type) bool {
+	return rt.PkgPath() == "" && rt.Name() != ""
 }
 
 type eface struct {
@@ -1,9 +1,13 @@
 package mask
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"net"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -148,6 +152,83 @@ func BenchmarkMask(b *testing.B) {
 	}
 }
 
+func BenchmarkMask_FlatStruct(b *testing.B) {
+	type Request struct {
+		ID        int64
+		Method    string
+		Path      string
+		UserAgent string
+		Status    int
+		Latency   float64
+		UserID    string `mask:"hash"`
+		Email     string `mask:"email"`
+		Token     string `mask:"filled"`
+		Phone     string `mask:"partial(last=4)"`
+		Amount    int    `mask:"random1000"`
+	}
+
+	m := newMasker()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		m.Mask(Request{
+			ID:        int64(n),
+			Method:    "GET",
+			Path:      "/users/123",
+			UserAgent: "Mozilla/5.0",
+			Status:    200,
+			Latency:   0.123,
+			UserID:    "123",
+			Email:     "usagi@example.com",
+			Token:     "secret-token",
+			Phone:     "090-1234-5678",
+			Amount:    100,
+		})
+	}
+}
+
+func BenchmarkMask_CopyPolicy(b *testing.B) {
+	type Item struct {
+		ID     int
+		Name   string
+		Tags   []string
+		Detail map[string]string
+	}
+	type Payload struct {
+		UserID string `mask:"hash"`
+		Items  []*Item
+	}
+
+	payload := Payload{UserID: "123"}
+	for i := 0; i < 1000; i++ {
+		payload.Items = append(payload.Items, &Item{
+			ID:     i,
+			Name:   fmt.Sprintf("item%d", i),
+			Tags:   []string{"a", "b", "c"},
+			Detail: map[string]string{"color": "red", "size": "M"},
+		})
+	}
+
+	for _, policy := range []struct {
+		name   string
+		policy CopyPolicy
+	}{
+		{name: "deep", policy: CopyDeep},
+		{name: "shallow", policy: CopyShallow},
+		{name: "share", policy: CopyShare},
+	} {
+		b.Run(policy.name, func(b *testing.B) {
+			m := newMasker()
+			m.SetCopyPolicy(policy.policy)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				m.Mask(payload)
+			}
+		})
+	}
+}
+
 func TestMask(t *testing.T) {
 	tests := map[string]struct {
 		prepare func(*Masker)
@@ -393,20 +474,20 @@ func TestMask_Primitive(t *testing.T) {
 				Tag: Tag{
 					String:     "test",
 					Int:        math.MaxInt,
-					Int8:       -1, // overflow
-					Int16:      -1, // overflow
-					Int32:      -1, // overflow
-					Int64:      math.MaxInt64,
+					Int8:       math.MaxInt8,
+					Int16:      math.MaxInt8,
+					Int32:      math.MaxInt8,
+					Int64:      math.MaxInt8,
 					Uint:       math.MaxUint,
-					Uint8:      math.MaxUint8,  // overflow
-					Uint16:     math.MaxUint16, // overflow
-					Uint32:     math.MaxUint32, // overflow
-					Uint64:     math.MaxUint64,
-					Float32:    float32(math.Inf(0)), // overflow
+					Uint8:      math.MaxUint8,
+					Uint16:     math.MaxUint8,
+					Uint32:     math.MaxUint8,
+					Uint64:     math.MaxUint8,
+					Float32:    math.MaxFloat32,
 					Float64:    math.MaxFloat64,
 					Complex64:  (1234 + 10i),
 					Complex128: (4321 + 20i),
-					Byte:       255, // overflow
+					Byte:       math.MaxUint8,
 				},
 				NoTag: input.NoTag,
 			},
@@ -436,20 +517,20 @@ func TestMask_Primitive(t *testing.T) {
 				NoTag: NoTag{
 					String:     "test",
 					Int:        math.MaxInt,
-					Int8:       -1, // overflow
-					Int16:      -1, // overflow
-					Int32:      -1, // overflow
-					Int64:      math.MaxInt64,
+					Int8:       math.MaxInt8,
+					Int16:      math.MaxInt8,
+					Int32:      math.MaxInt8,
+					Int64:      math.MaxInt8,
 					Uint:       math.MaxUint,
-					Uint8:      math.MaxUint8,  // overflow
-					Uint16:     math.MaxUint16, // overflow
-					Uint32:     math.MaxUint32, // overflow
-					Uint64:     math.MaxUint64,
-					Float32:    float32(math.Inf(0)), // overflow
+					Uint8:      math.MaxUint8,
+					Uint16:     math.MaxUint8,
+					Uint32:     math.MaxUint8,
+					Uint64:     math.MaxUint8,
+					Float32:    math.MaxFloat32,
 					Float64:    math.MaxFloat64,
 					Complex64:  (1234 + 10i),
 					Complex128: (4321 + 20i),
-					Byte:       255, // overflow
+					Byte:       math.MaxUint8,
 				},
 			},
 		},
@@ -560,8 +641,8 @@ func TestMask_Array(t *testing.T) {
 					Uint:           [3]uint{math.MaxUint, math.MaxUint, math.MaxUint},
 					Float64:        [3]float64{math.MaxFloat64, math.MaxFloat64, math.MaxFloat64},
 					Complex128:     [3]complex128{100 + 1i, 10i, 10},
-					Byte:           [3]byte{255, 255, 255},
-					CustomOfByte:   CustomArrayOfByte{255, 255, 255},
+					Byte:           [3]byte{'t', 'e', 's'},
+					CustomOfByte:   CustomArrayOfByte{'t', 'e', 's'},
 					Struct:         [3]Struct1{{"test"}, {"test"}, {"test"}},
 					CustomOfStruct: CustomArrayOfStruct{{"test"}, {"test"}, {"test"}},
 				},
@@ -599,8 +680,8 @@ func TestMask_Array(t *testing.T) {
 					Uint:           [3]uint{math.MaxUint, math.MaxUint, math.MaxUint},
 					Float64:        [3]float64{math.MaxFloat64, math.MaxFloat64, math.MaxFloat64},
 					Complex128:     [3]complex128{100 + 1i, 10i, 10},
-					Byte:           [3]byte{255, 255, 255},
-					CustomOfByte:   CustomArrayOfByte{255, 255, 255},
+					Byte:           [3]byte{'t', 'e', 's'},
+					CustomOfByte:   CustomArrayOfByte{'t', 'e', 's'},
 					Struct:         [3]Struct2{{"test"}, {"test"}, {"test"}},
 					CustomOfStruct: CustomArrayOfStruct2{{"test"}, {"test"}, {"test"}},
 				},
@@ -837,14 +918,14 @@ func TestMask_Slice(t *testing.T) {
 					Uint:             []uint{math.MaxUint, math.MaxUint, math.MaxUint},
 					Float64:          []float64{math.MaxFloat64, math.MaxFloat64, math.MaxFloat64},
 					Complex128:       []complex128{100 + 1i, 10i, 10},
-					Byte:             []byte{255, 255, 255},
+					Byte:             []byte("test"),
 					Struct:           []Struct1{{"test"}, {"test"}, {"test"}},
 					CustomString:     []string{"test", "test", "test"},
 					CustomInt:        []int{math.MaxInt, math.MaxInt, math.MaxInt},
 					CustomUint:       []uint{math.MaxUint, math.MaxUint, math.MaxUint},
 					CustomFloat64:    []float64{math.MaxFloat64, math.MaxFloat64, math.MaxFloat64},
 					CustomComplex128: []complex128{100 + 1i, 10i, 10},
-					CustomByte:       []byte{255, 255, 255},
+					CustomByte:       []byte("test"),
 					CustomStruct:     customStruct{{"test"}, {"test"}, {"test"}},
 				},
 				NoTag: input.NoTag,
@@ -897,14 +978,14 @@ func TestMask_Slice(t *testing.T) {
 					Uint:             []uint{math.MaxUint, math.MaxUint, math.MaxUint},
 					Float64:          []float64{math.MaxFloat64, math.MaxFloat64, math.MaxFloat64},
 					Complex128:       []complex128{100 + 1i, 10i, 10},
-					Byte:             []byte{255, 255, 255},
+					Byte:             []byte("test"),
 					Struct:           []Struct2{{"test"}, {"test"}, {"test"}},
 					CustomString:     []string{"test", "test", "test"},
 					CustomInt:        []int{math.MaxInt, math.MaxInt, math.MaxInt},
 					CustomUint:       []uint{math.MaxUint, math.MaxUint, math.MaxUint},
 					CustomFloat64:    []float64{math.MaxFloat64, math.MaxFloat64, math.MaxFloat64},
 					CustomComplex128: []complex128{100 + 1i, 10i, 10},
-					CustomByte:       []byte{255, 255, 255},
+					CustomByte:       []byte("test"),
 					CustomStruct:     customStruct2{{"test"}, {"test"}, {"test"}},
 				},
 			},
@@ -1075,7 +1156,7 @@ func TestMask_Map(t *testing.T) {
 					String:     map[string]string{"猿将": "test", "猿谷": "test", "猿桜": "test"},
 					Int:        map[string]int{"猿将": math.MaxInt, "猿谷": math.MaxInt, "猿桜": math.MaxInt},
 					Uint:       map[string]uint{"猿将": math.MaxUint, "猿谷": math.MaxUint, "猿桜": math.MaxUint},
-					Float32:    map[string]float32{"猿将": float32(math.Inf(0)), "猿谷": float32(math.Inf(0)), "猿桜": float32(math.Inf(0))},
+					Float32:    map[string]float32{"猿将": math.MaxFloat32, "猿谷": math.MaxFloat32, "猿桜": math.MaxFloat32},
 					Float64:    map[string]float64{"猿将": math.MaxFloat64, "猿谷": math.MaxFloat64, "猿桜": math.MaxFloat64},
 					Complex128: map[string]complex128{"猿将": 100 + 1i, "猿谷": 10i, "猿桜": 10},
 					Byte:       map[string]byte{"猿将": 255, "猿谷": 255, "猿桜": 255},
@@ -1085,7 +1166,7 @@ func TestMask_Map(t *testing.T) {
 					CustomString:     customString{"猿将": "test", "猿谷": "test", "猿桜": "test"},
 					CustomInt:        customInt{"猿将": math.MaxInt, "猿谷": math.MaxInt, "猿桜": math.MaxInt},
 					CustomUint:       customUint{"猿将": math.MaxUint, "猿谷": math.MaxUint, "猿桜": math.MaxUint},
-					CustomFloat32:    customFloat32{"猿将": float32(math.Inf(0)), "猿谷": float32(math.Inf(0)), "猿桜": float32(math.Inf(0))},
+					CustomFloat32:    customFloat32{"猿将": math.MaxFloat32, "猿谷": math.MaxFloat32, "猿桜": math.MaxFloat32},
 					CustomFloat64:    customFloat64{"猿将": math.MaxFloat64, "猿谷": math.MaxFloat64, "猿桜": math.MaxFloat64},
 					CustomComplex128: customComplex128{"猿将": 100 + 1i, "猿谷": 10i, "猿桜": 10},
 					CustomByte:       customByte{"猿将": 255, "猿谷": 255, "猿桜": 255},
@@ -1145,7 +1226,7 @@ func TestMask_Map(t *testing.T) {
 					String:     map[string]string{"猿将": "test", "猿谷": "test", "猿桜": "test"},
 					Int:        map[string]int{"猿将": math.MaxInt, "猿谷": math.MaxInt, "猿桜": math.MaxInt},
 					Uint:       map[string]uint{"猿将": math.MaxUint, "猿谷": math.MaxUint, "猿桜": math.MaxUint},
-					Float32:    map[string]float32{"猿将": float32(math.Inf(0)), "猿谷": float32(math.Inf(0)), "猿桜": float32(math.Inf(0))},
+					Float32:    map[string]float32{"猿将": math.MaxFloat32, "猿谷": math.MaxFloat32, "猿桜": math.MaxFloat32},
 					Float64:    map[string]float64{"猿将": math.MaxFloat64, "猿谷": math.MaxFloat64, "猿桜": math.MaxFloat64},
 					Complex128: map[string]complex128{"猿将": 100 + 1i, "猿谷": 10i, "猿桜": 10},
 					Byte:       map[string]byte{"猿将": 255, "猿谷": 255, "猿桜": 255},
@@ -1155,7 +1236,7 @@ func TestMask_Map(t *testing.T) {
 					CustomString:     customString{"猿将": "test", "猿谷": "test", "猿桜": "test"},
 					CustomInt:        customInt{"猿将": math.MaxInt, "猿谷": math.MaxInt, "猿桜": math.MaxInt},
 					CustomUint:       customUint{"猿将": math.MaxUint, "猿谷": math.MaxUint, "猿桜": math.MaxUint},
-					CustomFloat32:    customFloat32{"猿将": float32(math.Inf(0)), "猿谷": float32(math.Inf(0)), "猿桜": float32(math.Inf(0))},
+					CustomFloat32:    customFloat32{"猿将": math.MaxFloat32, "猿谷": math.MaxFloat32, "猿桜": math.MaxFloat32},
 					CustomFloat64:    customFloat64{"猿将": math.MaxFloat64, "猿谷": math.MaxFloat64, "猿桜": math.MaxFloat64},
 					CustomComplex128: customComplex128{"猿将": 100 + 1i, "猿谷": 10i, "猿桜": 10},
 					CustomByte:       customByte{"猿将": 255, "猿谷": 255, "猿桜": 255},
@@ -1662,11 +1743,310 @@ func registerTestMaskFunc(m *Masker, tag string) {
 	m.RegisterMaskUintFunc(tag, func(arg string, value uint) (uint, error) {
 		return math.MaxUint, nil
 	})
+	// the sized kinds prefer the functions for int64 and uint64, and their results must fit in the smallest kinds
+	m.RegisterMaskInt64Func(tag, func(arg string, value int64) (int64, error) {
+		return math.MaxInt8, nil
+	})
+	m.RegisterMaskUint64Func(tag, func(arg string, value uint64) (uint64, error) {
+		return math.MaxUint8, nil
+	})
+	m.RegisterMaskFloat32Func(tag, func(arg string, value float32) (float32, error) {
+		return math.MaxFloat32, nil
+	})
 	m.RegisterMaskFloat64Func(tag, func(arg string, value float64) (float64, error) {
 		return math.MaxFloat64, nil
 	})
 }
 
+func TestMask_Concurrent(t *testing.T) {
+	type Address struct {
+		PostCode string `mask:"filled"`
+		City     string
+	}
+	type User struct {
+		ID      int
+		Name    string `mask:"fixed"`
+		Email   string `mask:"email"`
+		Address Address
+		Tags    []string `mask:"filled"`
+	}
+	newInput := func(i int) User {
+		return User{
+			ID:      i,
+			Name:    fmt.Sprintf("Usagi%d", i),
+			Email:   fmt.Sprintf("usagi%d@example.com", i),
+			Address: Address{PostCode: "123-4567", City: fmt.Sprintf("City%d", i)},
+			Tags:    []string{fmt.Sprintf("tag%d", i)},
+		}
+	}
+	newWant := func(i int) User {
+		return User{
+			ID:      i,
+			Name:    "********",
+			Email:   strings.Repeat("*", len(fmt.Sprint(i))+5) + "@example.com",
+			Address: Address{PostCode: "********", City: fmt.Sprintf("City%d", i)},
+			Tags:    []string{strings.Repeat("*", len(fmt.Sprint(i))+3)},
+		}
+	}
+
+	for _, cache := range []bool{true, false} {
+		t.Run(fmt.Sprintf("cache enable=%t", cache), func(t *testing.T) {
+			m := newMasker()
+			m.Cache(cache)
+
+			const goroutines, iterations = 16, 200
+			var wg sync.WaitGroup
+			errs := make(chan string, goroutines)
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for n := 0; n < iterations; n++ {
+						i := g*iterations + n
+						got, err := m.Mask(newInput(i))
+						if err != nil {
+							errs <- err.Error()
+							return
+						}
+						if diff := cmp.Diff(newWant(i), got); diff != "" {
+							errs <- diff
+							return
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMask_PlanInvalidation(t *testing.T) {
+	type structTest struct {
+		Usagi     string `mask:"custom" fake:"fixed"`
+		Hachiware int    `mask:"custom"`
+		Chiikawa  string
+	}
+	input := structTest{Usagi: "Usagi", Hachiware: 1, Chiikawa: "Chiikawa"}
+
+	m := newMasker()
+	got, err := m.Mask(input)
+	assert.NoError(t, err)
+	assert.Equal(t, input, got)
+
+	m.RegisterMaskStringFunc("custom", func(arg, value string) (string, error) {
+		return "custom", nil
+	})
+	m.RegisterMaskAnyFunc("custom", func(arg string, value any) (any, error) {
+		return 100, nil
+	})
+	got, err = m.Mask(input)
+	assert.NoError(t, err)
+	assert.Equal(t, structTest{Usagi: "custom", Hachiware: 100, Chiikawa: "Chiikawa"}, got)
+
+	m.RegisterMaskField("Chiikawa", MaskTypeFilled)
+	got, err = m.Mask(input)
+	assert.NoError(t, err)
+	assert.Equal(t, structTest{Usagi: "custom", Hachiware: 100, Chiikawa: "********"}, got)
+
+	m.SetTagName("fake")
+	got, err = m.Mask(input)
+	assert.NoError(t, err)
+	assert.Equal(t, structTest{Usagi: "********", Hachiware: 1, Chiikawa: "********"}, got)
+}
+
+func TestMask_ElementPlan(t *testing.T) {
+	type Secret string
+	type structTest struct {
+		Strings  []string          `mask:"filled"`
+		Int8s    [2]int8           `mask:"random1"`
+		Secrets  []Secret          `mask:"filled"`
+		Int8Map  map[string]int8   `mask:"random1"`
+		Names    map[string]string `mask:"fixed"`
+		Codes    map[string]Secret `mask:"filled"`
+		Unknowns []string          `mask:"unknown"`
+		Nil      []string          `mask:"filled"`
+	}
+	input := structTest{
+		Strings:  []string{"Usagi", "Chiikawa"},
+		Int8s:    [2]int8{1, 2},
+		Secrets:  []Secret{"Hachiware"},
+		Int8Map:  map[string]int8{"Usagi": 100},
+		Names:    map[string]string{"Usagi": "Chiikawa"},
+		Codes:    map[string]Secret{"Usagi": "Momonga"},
+		Unknowns: []string{"Usagi"},
+	}
+	want := structTest{
+		Strings:  []string{"*****", "********"},
+		Int8s:    [2]int8{0, 0},
+		Secrets:  []Secret{"*********"},
+		Int8Map:  map[string]int8{"Usagi": 0},
+		Names:    map[string]string{"Usagi": "********"},
+		Codes:    map[string]Secret{"Usagi": "*******"},
+		Unknowns: []string{"Usagi"},
+	}
+
+	for _, cache := range []bool{true, false} {
+		t.Run(newMaskerTestCase(fmt.Sprintf("cache %t", cache)), func(t *testing.T) {
+			m := newMasker()
+			m.Cache(cache)
+			m.SetRand(rand.New(rand.NewSource(1)))
+			got, err := m.Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Error(diff)
+			}
+			assert.Equal(t, []string{"Usagi", "Chiikawa"}, input.Strings)
+
+			// a function for any type registered later masks the slice as a whole
+			m.RegisterMaskAnyFunc(MaskTypeFilled, func(arg string, value any) (any, error) {
+				return reflect.Zero(reflect.TypeOf(value)).Interface(), nil
+			})
+			got, err = m.Mask(input)
+			assert.NoError(t, err)
+			assert.Nil(t, got.(structTest).Strings)
+			assert.Nil(t, got.(structTest).Secrets)
+		})
+	}
+}
+
+// copyPolicyA and copyPolicyB refer to each other, which types in a function cannot.
+type copyPolicyA struct {
+	B     *copyPolicyB
+	Usagi string `mask:"filled"`
+}
+
+type copyPolicyB struct {
+	As []copyPolicyA
+}
+
+func TestSetCopyPolicy(t *testing.T) {
+	type Item struct {
+		Name  string
+		Price int
+	}
+	type structTest struct {
+		Usagi  string `mask:"filled"`
+		Items  []Item
+		ByName map[string]Item
+		First  *Item
+		Any    any
+		Nil    []Item
+	}
+	newInput := func() structTest {
+		items := []Item{{Name: "Chiikawa", Price: 100}, {Name: "Hachiware", Price: 200}}
+		return structTest{
+			Usagi:  "Usagi",
+			Items:  items,
+			ByName: map[string]Item{"Chiikawa": items[0]},
+			First:  &items[0],
+			Any:    []*Item{&items[1]},
+		}
+	}
+	want := newInput()
+	want.Usagi = "*****"
+
+	tests := map[string]struct {
+		policy CopyPolicy
+		// shared reports whether each field is expected to share memory with the target
+		sharedTop   bool
+		sharedInner bool
+	}{
+		"deep": {
+			policy: CopyDeep,
+		},
+		"shallow": {
+			policy:      CopyShallow,
+			sharedInner: true,
+		},
+		"share": {
+			policy:      CopyShare,
+			sharedTop:   true,
+			sharedInner: true,
+		},
+	}
+
+	for name, tt := range tests {
+		assertShared := func(t *testing.T, input, got structTest) {
+			t.Helper()
+			assert.Equal(t, tt.sharedTop, &input.Items[0] == &got.Items[0], "Items")
+			assert.Equal(t, tt.sharedTop, reflect.ValueOf(input.ByName).Pointer() == reflect.ValueOf(got.ByName).Pointer(), "ByName")
+			assert.Equal(t, tt.sharedTop, input.First == got.First, "First")
+			assert.Equal(t, tt.sharedInner, input.Any.([]*Item)[0] == got.Any.([]*Item)[0], "Any")
+		}
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			SetCopyPolicy(tt.policy)
+			input := newInput()
+			got, err := Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Error(diff)
+			}
+			assertShared(t, input, got)
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.SetCopyPolicy(tt.policy)
+			input := newInput()
+			got, err := m.Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Error(diff)
+			}
+			assertShared(t, input, got.(structTest))
+		})
+	}
+
+	t.Run(newMaskerTestCase("cyclic types"), func(t *testing.T) {
+		type Node struct {
+			Name string
+			Next *Node
+		}
+		node := &Node{Name: "Chiikawa"}
+		node.Next = node
+
+		for _, cache := range []bool{true, false} {
+			m := newMasker()
+			m.Cache(cache)
+			m.SetCopyPolicy(CopyShare)
+			// copyPolicyB is analyzed while copyPolicyA is being analyzed
+			got, err := m.Mask([]any{
+				copyPolicyA{B: &copyPolicyB{As: []copyPolicyA{{Usagi: "Usagi"}}}},
+				&copyPolicyB{As: []copyPolicyA{{Usagi: "Usagi"}}},
+				node,
+			})
+			assert.NoError(t, err)
+			want := []any{
+				copyPolicyA{B: &copyPolicyB{As: []copyPolicyA{{Usagi: "*****"}}}},
+				&copyPolicyB{As: []copyPolicyA{{Usagi: "*****"}}},
+				node,
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Error(diff)
+			}
+			assert.Same(t, node, got.([]any)[2])
+		}
+	})
+
+	t.Run(newMaskerTestCase("field name"), func(t *testing.T) {
+		m := newMasker()
+		m.SetCopyPolicy(CopyShare)
+		input := map[string]string{"Usagi": "Usagi"}
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, input, got)
+
+		m.RegisterMaskField("Usagi", MaskTypeFilled)
+		got, err = m.Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"Usagi": "*****"}, got)
+	})
+}
+
 func TestSetTagName(t *testing.T) {
 	t.Run("change a tag name", func(t *testing.T) {
 		m := newMasker()
@@ -1923,6 +2303,185 @@ func TestMaskFixed(t *testing.T) {
 	}
 }
 
+func TestMaskPartial(t *testing.T) {
+	type stringTest struct {
+		Usagi string `mask:"partial2,4"`
+	}
+	type stringFirstTest struct {
+		Usagi string `mask:"partial2"`
+	}
+	type stringLastTest struct {
+		Usagi string `mask:"partial,4"`
+	}
+	type stringSliceTest struct {
+		Usagi []string `mask:"partial1,1"`
+	}
+
+	tests := map[string]struct {
+		input any
+		want  any
+	}{
+		"string fields": {
+			input: &stringTest{Usagi: "4111111111111111"},
+			want:  &stringTest{Usagi: "41**********1111"},
+		},
+		"multibyte string fields": {
+			input: &stringTest{Usagi: "ヤハッ！ウラウラ"},
+			want:  &stringTest{Usagi: "ヤハ**ウラウラ"},
+		},
+		"zero string fields": {
+			input: &stringTest{},
+			want:  &stringTest{Usagi: ""},
+		},
+		"too short string fields": {
+			input: &stringTest{Usagi: "ヤハッ！"},
+			want:  &stringTest{Usagi: "****"},
+		},
+		"keep first only": {
+			input: &stringFirstTest{Usagi: "ヤハッ！"},
+			want:  &stringFirstTest{Usagi: "ヤハ**"},
+		},
+		"keep last only": {
+			input: &stringLastTest{Usagi: "4111111111111111"},
+			want:  &stringLastTest{Usagi: "************1111"},
+		},
+		"string slice fields": {
+			input: &stringSliceTest{Usagi: []string{"ハァ？", "ウラ", "フゥン"}},
+			want:  &stringSliceTest{Usagi: []string{"ハ*？", "**", "フ*ン"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			got, err := Mask(tt.input)
+			assert.Nil(t, err)
+			if diff := cmp.Diff(tt.want, got, allowUnexported(tt.input)); diff != "" {
+				t.Error(diff)
+			}
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			got, err := m.Mask(tt.input)
+			assert.Nil(t, err)
+			if diff := cmp.Diff(tt.want, got, allowUnexported(tt.input)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		m := newMasker()
+		for _, arg := range []string{"", "a", "1,b", "-1", "1,2,3"} {
+			_, err := m.MaskPartialString(arg, "ヤハッ！")
+			var argErr *InvalidArgError
+			if !errors.As(err, &argErr) {
+				t.Errorf("arg=%q: want an InvalidArgError, got %v", arg, err)
+				continue
+			}
+			assert.Equal(t, MaskTypePartial, argErr.MaskType)
+			assert.Equal(t, arg, argErr.Arg)
+		}
+	})
+
+	t.Run("counts that overflow when added", func(t *testing.T) {
+		m := newMasker()
+		for _, tag := range []string{
+			fmt.Sprintf("partial%d,%d", math.MaxInt, math.MaxInt),
+			fmt.Sprintf("partial(keep=%d,from=both)", math.MaxInt),
+		} {
+			got, err := m.String(tag, "abcdef")
+			assert.NoError(t, err, tag)
+			assert.Equal(t, "******", got, tag)
+		}
+	})
+}
+
+func TestMaskEmail(t *testing.T) {
+	type stringTest struct {
+		Usagi string `mask:"email"`
+	}
+	type stringKeepFirstTest struct {
+		Usagi string `mask:"email1"`
+	}
+	type stringDomainTest struct {
+		Usagi string `mask:"email1,domain"`
+	}
+	type stringMapTest struct {
+		Usagi map[string]string `mask:"email,domain"`
+	}
+
+	tests := map[string]struct {
+		input any
+		want  any
+	}{
+		"string fields": {
+			input: &stringTest{Usagi: "usagi@example.com"},
+			want:  &stringTest{Usagi: "*****@example.com"},
+		},
+		"zero string fields": {
+			input: &stringTest{},
+			want:  &stringTest{Usagi: ""},
+		},
+		"not an email address": {
+			input: &stringTest{Usagi: "ヤハッ！"},
+			want:  &stringTest{Usagi: "****"},
+		},
+		"keep first character": {
+			input: &stringKeepFirstTest{Usagi: "うさぎ@example.com"},
+			want:  &stringKeepFirstTest{Usagi: "う**@example.com"},
+		},
+		"keep first character of a short local part": {
+			input: &stringKeepFirstTest{Usagi: "u@example.com"},
+			want:  &stringKeepFirstTest{Usagi: "*@example.com"},
+		},
+		"mask domain": {
+			input: &stringDomainTest{Usagi: "usagi@mail.example.co.jp"},
+			want:  &stringDomainTest{Usagi: "u****@****.*******.**.jp"},
+		},
+		"mask domain without a top-level domain": {
+			input: &stringDomainTest{Usagi: "usagi@localhost"},
+			want:  &stringDomainTest{Usagi: "u****@*********"},
+		},
+		"string map fields": {
+			input: &stringMapTest{Usagi: map[string]string{"うさぎ": "usagi@example.com"}},
+			want:  &stringMapTest{Usagi: map[string]string{"うさぎ": "*****@*******.com"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			got, err := Mask(tt.input)
+			assert.Nil(t, err)
+			if diff := cmp.Diff(tt.want, got, allowUnexported(tt.input)); diff != "" {
+				t.Error(diff)
+			}
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			got, err := m.Mask(tt.input)
+			assert.Nil(t, err)
+			if diff := cmp.Diff(tt.want, got, allowUnexported(tt.input)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		m := newMasker()
+		for _, arg := range []string{"a", "-1", "1,host"} {
+			_, err := m.MaskEmailString(arg, "usagi@example.com")
+			var argErr *InvalidArgError
+			if !errors.As(err, &argErr) {
+				t.Errorf("arg=%q: want an InvalidArgError, got %v", arg, err)
+				continue
+			}
+			assert.Equal(t, MaskTypeEmail, argErr.MaskType)
+		}
+	})
+}
+
 func TestMaskHashString(t *testing.T) {
 	type stringTest struct {
 		Usagi string `mask:"hash"`
@@ -2089,31 +2648,109 @@ func TestMaskHashString(t *testing.T) {
 	}
 }
 
-func TestMaskRandom(t *testing.T) {
-	type intTest struct {
-		Usagi int `mask:"random1000"`
-	}
-	type int16Test struct {
-		Usagi int32 `mask:"random1000"`
-	}
-	type int32Test struct {
-		Usagi int32 `mask:"random1000"`
-	}
-	type int64Test struct {
-		Usagi int64 `mask:"random1000"`
-	}
-	type intPtrTest struct {
-		Usagi *int `mask:"random1000"`
-	}
-	type intSliceTest struct {
-		Usagi []int `mask:"random1000"`
-	}
-	type customIntSlice []int
-	type customIntSliceTest struct {
-		Usagi customIntSlice `mask:"random1000"`
+func TestSetHashConfig(t *testing.T) {
+	type stringTest struct {
+		Usagi string `mask:"hash"`
 	}
-	type int32SliceTest struct {
-		Usagi []int32 `mask:"random1000"`
+
+	tests := map[string]struct {
+		config HashConfig
+		input  any
+		want   any
+		err    error
+	}{
+		"sha1": {
+			config: HashConfig{},
+			input:  &stringTest{Usagi: "ヤハッ！"},
+			want:   &stringTest{Usagi: "a6ab5728db57954641b2e155adc61f2cbdfc7063"},
+		},
+		"hmac-sha256": {
+			config: HashConfig{Algorithm: HashHMACSHA256, Key: []byte("secret")},
+			input:  &stringTest{Usagi: "ヤハッ！"},
+			want:   &stringTest{Usagi: "ecc345d62f91f1bf8ae43a47134479f1a44e3bed7a53924305d28cdab803e3ef"},
+		},
+		"hmac-sha512": {
+			config: HashConfig{Algorithm: HashHMACSHA512, Key: []byte("secret")},
+			input:  &stringTest{Usagi: "ヤハッ！"},
+			want:   &stringTest{Usagi: "98667021f6fc2568be319ba16c43bf19559e8ef8fffd45a2451d9220f74695cf970c7c617d14c40437630481b340c17ffd6d836d0a2072d8f69f379674c38a1f"},
+		},
+		"truncate and prefix": {
+			config: HashConfig{Algorithm: HashHMACSHA256, Key: []byte("secret"), Length: 12, Prefix: "usr_"},
+			input:  &stringTest{Usagi: "ヤハッ！"},
+			want:   &stringTest{Usagi: "usr_ecc345d62f91"},
+		},
+		"hmac without a key": {
+			config: HashConfig{Algorithm: HashHMACSHA256},
+			input:  &stringTest{Usagi: "ヤハッ！"},
+			err:    ErrHashKeyRequired,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			SetHashConfig(tt.config)
+			got, err := Mask(tt.input)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			if diff := cmp.Diff(tt.want, got, allowUnexported(tt.input)); diff != "" {
+				t.Error(diff)
+			}
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.SetHashConfig(tt.config)
+			got, err := m.Mask(tt.input)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			if diff := cmp.Diff(tt.want, got, allowUnexported(tt.input)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("copy the key", func(t *testing.T) {
+		m := newMasker()
+		key := []byte("secret")
+		m.SetHashConfig(HashConfig{Algorithm: HashHMACSHA256, Key: key})
+		key[0] = 'S'
+		got, err := m.String(MaskTypeHash, "ヤハッ！")
+		assert.Nil(t, err)
+		assert.Equal(t, "ecc345d62f91f1bf8ae43a47134479f1a44e3bed7a53924305d28cdab803e3ef", got)
+	})
+}
+
+func TestMaskRandom(t *testing.T) {
+	type intTest struct {
+		Usagi int `mask:"random1000"`
+	}
+	type int16Test struct {
+		Usagi int32 `mask:"random1000"`
+	}
+	type int32Test struct {
+		Usagi int32 `mask:"random1000"`
+	}
+	type int64Test struct {
+		Usagi int64 `mask:"random1000"`
+	}
+	type intPtrTest struct {
+		Usagi *int `mask:"random1000"`
+	}
+	type intSliceTest struct {
+		Usagi []int `mask:"random1000"`
+	}
+	type customIntSlice []int
+	type customIntSliceTest struct {
+		Usagi customIntSlice `mask:"random1000"`
+	}
+	type int32SliceTest struct {
+		Usagi []int32 `mask:"random1000"`
 	}
 	type int64SliceTest struct {
 		Usagi []int64 `mask:"random1000"`
@@ -2171,6 +2808,15 @@ func TestMaskRandom(t *testing.T) {
 	type customStringToMapTest struct {
 		Usagi stringToInt `mask:"random1000"`
 	}
+	type uintTest struct {
+		Usagi uint `mask:"random1000"`
+	}
+	type uint16Test struct {
+		Usagi uint16 `mask:"random1000"`
+	}
+	type uint64SliceTest struct {
+		Usagi []uint64 `mask:"random1000"`
+	}
 
 	tests := map[string]struct {
 		input any
@@ -2308,6 +2954,18 @@ func TestMaskRandom(t *testing.T) {
 			input: &customStringToMapTest{Usagi: stringToInt{"うさぎ": 20190122}},
 			want:  &customStringToMapTest{Usagi: stringToInt{"うさぎ": 829}},
 		},
+		"uint fields": {
+			input: &uintTest{Usagi: 20190122},
+			want:  &uintTest{Usagi: 829},
+		},
+		"uint16 fields": {
+			input: &uint16Test{Usagi: 2019},
+			want:  &uint16Test{Usagi: 829},
+		},
+		"uint64 slice fields": {
+			input: &uint64SliceTest{Usagi: []uint64{20190122, 20200501, 20200501}},
+			want:  &uint64SliceTest{Usagi: []uint64{829, 830, 400}},
+		},
 	}
 
 	for name, tt := range tests {
@@ -2334,6 +2992,723 @@ func TestMaskRandom(t *testing.T) {
 	}
 }
 
+func TestMaskRandom_Overflow(t *testing.T) {
+	type int8Test struct {
+		Usagi int8 `mask:"random1000"`
+	}
+	type uint8SliceTest struct {
+		Usagi []uint8 `mask:"random1000"`
+	}
+	type pseudoTest struct {
+		Usagi int16 `mask:"pseudo(max=100000)"`
+	}
+	type uint16MapTest struct {
+		Usagi map[string]uint16 `mask:"random65537"`
+	}
+	type fitTest struct {
+		Int8   int8   `mask:"random128"`
+		Uint8  uint8  `mask:"pseudo256"`
+		Uint16 uint16 `mask:"random65536"`
+	}
+
+	tests := map[string]struct {
+		input any
+		isErr bool
+	}{
+		"int8 fields": {
+			input: &int8Test{Usagi: 1},
+			isErr: true,
+		},
+		"uint8 slice fields": {
+			input: &uint8SliceTest{Usagi: []uint8{1, 2}},
+			isErr: true,
+		},
+		"pseudo": {
+			input: &pseudoTest{Usagi: 1},
+			isErr: true,
+		},
+		"uint16 map values": {
+			input: &uint16MapTest{Usagi: map[string]uint16{"Chiikawa": 1}},
+			isErr: true,
+		},
+		"ranges that fit": {
+			input: &fitTest{Int8: 1, Uint8: 1, Uint16: 1},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.SetPseudoKey([]byte("secret"))
+			// the range is checked against the type, so the result does not depend on the numbers drawn
+			for i := 0; i < 100; i++ {
+				_, err := m.Mask(tt.input)
+				if !tt.isErr {
+					assert.NoError(t, err)
+					continue
+				}
+				var overflowErr *OverflowError
+				if assert.ErrorAs(t, err, &overflowErr) {
+					assert.Contains(t, overflowErr.Error(), "overflows")
+				}
+			}
+		})
+	}
+}
+
+func TestSizedMaskFunc(t *testing.T) {
+	type Test struct {
+		Int     int
+		Int8    int8
+		Int64   int64
+		Uint    uint
+		Uint8   uint8
+		Uint32  uint32
+		Uint64  uint64
+		Float32 float32
+		Float64 float64
+	}
+	input := Test{Int: 1, Int8: 1, Int64: 1, Uint: 1, Uint8: 1, Uint32: 1, Uint64: 1, Float32: 1, Float64: 1}
+
+	tests := map[string]struct {
+		prepare func(*Masker)
+		want    Test
+		isErr   bool
+	}{
+		"sized functions take precedence over int, uint and float64 functions": {
+			prepare: func(m *Masker) {
+				registerTestMaskFunc(m, "test")
+				m.RegisterMaskInt64Func("test", func(arg string, value int64) (int64, error) {
+					return 64, nil
+				})
+				m.RegisterMaskUint64Func("test", func(arg string, value uint64) (uint64, error) {
+					return 64, nil
+				})
+				m.RegisterMaskFloat32Func("test", func(arg string, value float32) (float32, error) {
+					return 32, nil
+				})
+			},
+			want: Test{
+				Int:     math.MaxInt,
+				Int8:    64,
+				Int64:   64,
+				Uint:    math.MaxUint,
+				Uint8:   64,
+				Uint32:  64,
+				Uint64:  64,
+				Float32: 32,
+				Float64: math.MaxFloat64,
+			},
+		},
+		"int and uint values fall back to sized functions": {
+			prepare: func(m *Masker) {
+				m.RegisterMaskInt64Func("test", func(arg string, value int64) (int64, error) {
+					return value + 63, nil
+				})
+				m.RegisterMaskUint64Func("test", func(arg string, value uint64) (uint64, error) {
+					return value + 63, nil
+				})
+			},
+			want: Test{Int: 64, Int8: 64, Int64: 64, Uint: 64, Uint8: 64, Uint32: 64, Uint64: 64, Float32: 1, Float64: 1},
+		},
+		"overflow of an int64 function": {
+			prepare: func(m *Masker) {
+				m.RegisterMaskInt64Func("test", func(arg string, value int64) (int64, error) {
+					return math.MaxInt64, nil
+				})
+			},
+			isErr: true,
+		},
+		"overflow of a uint64 function": {
+			prepare: func(m *Masker) {
+				m.RegisterMaskUint64Func("test", func(arg string, value uint64) (uint64, error) {
+					return math.MaxUint64, nil
+				})
+			},
+			isErr: true,
+		},
+		"overflow of an int function": {
+			prepare: func(m *Masker) {
+				m.RegisterMaskIntFunc("test", func(arg string, value int) (int, error) {
+					return 1000, nil
+				})
+			},
+			isErr: true,
+		},
+		"overflow of a uint function": {
+			prepare: func(m *Masker) {
+				m.RegisterMaskUintFunc("test", func(arg string, value uint) (uint, error) {
+					return 1000, nil
+				})
+			},
+			isErr: true,
+		},
+		"overflow of a float64 function": {
+			prepare: func(m *Masker) {
+				m.RegisterMaskFloat64Func("test", func(arg string, value float64) (float64, error) {
+					return 1e300, nil
+				})
+			},
+			isErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := NewMasker()
+			m.SetTagName("test")
+			tt.prepare(m)
+			for _, field := range []string{"Int", "Int8", "Int64", "Uint", "Uint8", "Uint32", "Uint64", "Float32", "Float64"} {
+				m.RegisterMaskField(field, "test")
+			}
+			got, err := m.Mask(input)
+			if tt.isErr {
+				var overflowErr *OverflowError
+				assert.ErrorAs(t, err, &overflowErr)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("Int64", func(t *testing.T) {
+		m := newMasker()
+		m.SetRand(rand.New(rand.NewSource(rand.NewSource(1).Int63())))
+		got, err := m.Int64("random1000", math.MaxInt64)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(829), got)
+	})
+	t.Run("random range of int", func(t *testing.T) {
+		m := newMasker()
+		got, err := m.MaskRandomInt("5000000000", 1)
+		if math.MaxInt < 5000000000 {
+			var argErr *InvalidArgError
+			assert.ErrorAs(t, err, &argErr)
+			return
+		}
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, got, 0)
+	})
+	t.Run("pseudo range of int", func(t *testing.T) {
+		m := newMasker()
+		m.SetPseudoKey([]byte("secret"))
+		got, err := m.MaskPseudoInt("5000000000", 1)
+		if math.MaxInt < 5000000000 {
+			var argErr *InvalidArgError
+			assert.ErrorAs(t, err, &argErr)
+			return
+		}
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, got, 0)
+	})
+	t.Run("Uint64", func(t *testing.T) {
+		m := newMasker()
+		m.SetRand(rand.New(rand.NewSource(rand.NewSource(1).Int63())))
+		got, err := m.Uint64("random1000", math.MaxUint64)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(829), got)
+	})
+	t.Run("Float32", func(t *testing.T) {
+		m := newMasker()
+		m.SetRand(rand.New(rand.NewSource(rand.NewSource(1).Int63())))
+		got, err := m.Float32("random100000.4", math.MaxFloat32)
+		assert.NoError(t, err)
+		assert.Equal(t, float32(96011.8989), got)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		m := newMasker()
+		for _, tag := range []string{"random", "randomabc", "random0"} {
+			_, err := m.Int64(tag, 1)
+			var argErr *InvalidArgError
+			assert.ErrorAs(t, err, &argErr, tag)
+		}
+	})
+}
+
+func TestSetRand(t *testing.T) {
+	type structTest struct {
+		Int     int       `mask:"random1000"`
+		Float64 float64   `mask:"random100000.4"`
+		Slice   []float32 `mask:"random100000.4"`
+	}
+	input := structTest{
+		Int:     20190122,
+		Float64: 20190122,
+		Slice:   []float32{20190122, 20200501},
+	}
+	want := structTest{
+		Int:     829,
+		Float64: 90863.3149,
+		Slice:   []float32{32310.0201, 73483.9274},
+	}
+
+	t.Run(defaultTestCase("rand source"), func(t *testing.T) {
+		defer cleanup(t)
+		SetRand(rand.New(rand.NewSource(rand.NewSource(1).Int63())))
+		got, err := Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("rand source"), func(t *testing.T) {
+		m := newMasker()
+		m.SetRand(rand.New(rand.NewSource(rand.NewSource(1).Int63())))
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("reset to math/rand"), func(t *testing.T) {
+		m := newMasker()
+		m.SetRand(rand.New(rand.NewSource(0)))
+		m.SetRand(nil)
+		rand.Seed(rand.NewSource(1).Int63())
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestSetUnmatchedPolicy(t *testing.T) {
+	type structTest struct {
+		String  string         `mask:"unknown"`
+		Int     int            `mask:"filled"`
+		Uint8   uint8          `mask:"email"`
+		Float32 float32        `mask:"hash"`
+		Bool    bool           `mask:"filled"`
+		Time    time.Time      `mask:"filled"`
+		Bools   []bool         `mask:"filled"`
+		Map     map[string]int `mask:"unknown"`
+		Masked  string         `mask:"filled"`
+		NoTag   string
+	}
+	input := structTest{
+		String:  "Usagi",
+		Int:     20190122,
+		Uint8:   1,
+		Float32: 1.5,
+		Bool:    true,
+		Time:    time.Date(2019, 1, 22, 0, 0, 0, 0, time.UTC),
+		Bools:   []bool{true, false},
+		Map:     map[string]int{"Chiikawa": 1},
+		Masked:  "Chiikawa",
+		NoTag:   "Momonga",
+	}
+
+	tests := map[string]struct {
+		policy  UnmatchedPolicy
+		want    structTest
+		wantErr error
+	}{
+		"keep": {
+			policy: UnmatchedKeep,
+			want: structTest{
+				String:  "Usagi",
+				Int:     20190122,
+				Uint8:   1,
+				Float32: 1.5,
+				Bool:    true,
+				Time:    time.Date(2019, 1, 22, 0, 0, 0, 0, time.UTC),
+				Bools:   []bool{true, false},
+				Map:     map[string]int{"Chiikawa": 1},
+				Masked:  "********",
+				NoTag:   "Momonga",
+			},
+		},
+		"zero": {
+			policy: UnmatchedZero,
+			want: structTest{
+				Bools:  make([]bool, 2),
+				Map:    map[string]int{"Chiikawa": 0},
+				Masked: "********",
+				NoTag:  "Momonga",
+			},
+		},
+		"fail": {
+			policy:  UnmatchedFail,
+			wantErr: ErrUnregisteredMaskType,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			SetUnmatchedPolicy(tt.policy)
+			got, err := Mask(input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.SetUnmatchedPolicy(tt.policy)
+			got, err := m.Mask(input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	failTests := map[string]struct {
+		input   any
+		wantErr error
+	}{
+		"unregistered mask type": {
+			input: struct {
+				String string `mask:"unknown"`
+			}{String: "Usagi"},
+			wantErr: ErrUnregisteredMaskType,
+		},
+		"int": {
+			input: struct {
+				Int int `mask:"filled"`
+			}{Int: 1},
+			wantErr: ErrIncompatibleMaskType,
+		},
+		"sized uint": {
+			input: struct {
+				Uint8 uint8 `mask:"email"`
+			}{Uint8: 1},
+			wantErr: ErrIncompatibleMaskType,
+		},
+		"float32": {
+			input: struct {
+				Float32 float32 `mask:"hash"`
+			}{Float32: 1},
+			wantErr: ErrIncompatibleMaskType,
+		},
+		"bool": {
+			input: struct {
+				Bool bool `mask:"filled"`
+			}{Bool: true},
+			wantErr: ErrIncompatibleMaskType,
+		},
+		"struct": {
+			input: struct {
+				Time time.Time `mask:"filled"`
+			}{Time: time.Date(2019, 1, 22, 0, 0, 0, 0, time.UTC)},
+			wantErr: ErrIncompatibleMaskType,
+		},
+		"slice": {
+			input: struct {
+				Bools []bool `mask:"filled"`
+			}{Bools: []bool{true}},
+			wantErr: ErrIncompatibleMaskType,
+		},
+		"field name": {
+			input:   map[string]bool{"Usagi": true},
+			wantErr: ErrIncompatibleMaskType,
+		},
+	}
+	for name, tt := range failTests {
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.RegisterMaskField("Usagi", MaskTypeFilled)
+			m.SetUnmatchedPolicy(UnmatchedFail)
+			_, err := m.Mask(tt.input)
+			var unmatchedErr *UnmatchedTagError
+			assert.ErrorAs(t, err, &unmatchedErr)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run(newMaskerTestCase("value methods"), func(t *testing.T) {
+		m := newMasker()
+		m.SetUnmatchedPolicy(UnmatchedZero)
+		s, err := m.String("unknown", "Usagi")
+		assert.NoError(t, err)
+		assert.Equal(t, "", s)
+		i, err := m.Int("", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, i)
+
+		m.SetUnmatchedPolicy(UnmatchedFail)
+		_, err = m.Float64("filled", 1)
+		assert.ErrorIs(t, err, ErrIncompatibleMaskType)
+	})
+}
+
+func TestSetStructTagPolicy(t *testing.T) {
+	type Address struct {
+		Street   string
+		PostCode string `mask:"fixed"`
+		Number   int
+		private  string
+	}
+	type User struct {
+		Name     string
+		Address  Address   `mask:"filled"`
+		Previous *Address  `mask:"filled"`
+		Others   []Address `mask:"filled"`
+		Created  time.Time `mask:"filled"`
+		NoTag    Address
+	}
+	address := Address{Street: "Usagi", PostCode: "123-4567", Number: 1, private: "Chiikawa"}
+	input := User{
+		Name:     "Usagi",
+		Address:  address,
+		Previous: &address,
+		Others:   []Address{address},
+		Created:  time.Date(2019, 1, 22, 0, 0, 0, 0, time.UTC),
+		NoTag:    address,
+	}
+	masked := Address{Street: "*****", PostCode: "********", Number: 1, private: "Chiikawa"}
+	fixed := Address{Street: "Usagi", PostCode: "********", Number: 1, private: "Chiikawa"}
+
+	tests := map[string]struct {
+		policy  StructTagPolicy
+		want    User
+		wantErr error
+	}{
+		"ignore": {
+			policy: StructTagIgnore,
+			want: User{
+				Name:     "Usagi",
+				Address:  fixed,
+				Previous: &fixed,
+				Others:   []Address{fixed},
+				Created:  input.Created,
+				NoTag:    fixed,
+			},
+		},
+		"propagate": {
+			policy: StructTagPropagate,
+			want: User{
+				Name:     "Usagi",
+				Address:  masked,
+				Previous: &masked,
+				Others:   []Address{masked},
+				Created:  input.Created,
+				NoTag:    fixed,
+			},
+		},
+		"error": {
+			policy:  StructTagError,
+			wantErr: ErrIncompatibleMaskType,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			SetStructTagPolicy(tt.policy)
+			got, err := Mask(input)
+			if tt.wantErr != nil {
+				var unmatchedErr *UnmatchedTagError
+				assert.ErrorAs(t, err, &unmatchedErr)
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got, allowUnexported(Address{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.SetStructTagPolicy(tt.policy)
+			got, err := m.Mask(input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got, allowUnexported(Address{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run(newMaskerTestCase("propagate with unmatched policy"), func(t *testing.T) {
+		m := newMasker()
+		m.SetStructTagPolicy(StructTagPropagate)
+		m.SetUnmatchedPolicy(UnmatchedZero)
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		want := Address{Street: "*****", PostCode: "********", private: "Chiikawa"}
+		if diff := cmp.Diff(want, got.(User).Address, allowUnexported(Address{})); diff != "" {
+			t.Error(diff)
+		}
+		assert.Equal(t, time.Time{}, got.(User).Created)
+	})
+
+	t.Run(newMaskerTestCase("propagate to recursive type"), func(t *testing.T) {
+		type node struct {
+			Name string
+			Next *node
+		}
+		m := newMasker()
+		type nodeTest struct {
+			Node *node `mask:"filled"`
+		}
+		m.SetStructTagPolicy(StructTagPropagate)
+		input := nodeTest{Node: &node{Name: "Usagi", Next: &node{Name: "Chiikawa"}}}
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, "*****", got.(nodeTest).Node.Name)
+		assert.Equal(t, "********", got.(nodeTest).Node.Next.Name)
+		assert.NoError(t, m.Validate(input))
+	})
+
+	t.Run(newMaskerTestCase("validate"), func(t *testing.T) {
+		m := newMasker()
+		assert.Error(t, m.Validate(User{}))
+		m.SetStructTagPolicy(StructTagPropagate)
+		err := m.Validate(User{})
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr) && assert.Len(t, validationErr.Errors, 4) {
+			// Number cannot be filled and time.Time has no exported fields
+			for _, tagErr := range validationErr.Errors {
+				assert.ErrorIs(t, tagErr, ErrIncompatibleMaskType)
+			}
+		}
+	})
+}
+
+func TestMaskPseudo(t *testing.T) {
+	type intTest struct {
+		Usagi int `mask:"pseudo1000"`
+	}
+	type int64SliceTest struct {
+		Usagi []int64 `mask:"pseudo1000"`
+	}
+	type float64Test struct {
+		Usagi float64 `mask:"pseudo100.2"`
+	}
+	type float32SliceTest struct {
+		Usagi []float32 `mask:"pseudo100.2"`
+	}
+	type stringToIntTest struct {
+		Usagi map[string]int `mask:"pseudo1000"`
+	}
+
+	tests := map[string]struct {
+		input any
+		check func(t *testing.T, got any)
+	}{
+		"int fields": {
+			input: &intTest{Usagi: 20190122},
+			check: func(t *testing.T, got any) {
+				v := got.(*intTest).Usagi
+				assert.True(t, 0 <= v && v < 1000, v)
+			},
+		},
+		"int64 slice fields": {
+			input: &int64SliceTest{Usagi: []int64{20190122, 20200501, 20190122}},
+			check: func(t *testing.T, got any) {
+				v := got.(*int64SliceTest).Usagi
+				assert.Equal(t, v[0], v[2])
+				assert.NotEqual(t, v[0], v[1])
+			},
+		},
+		"float64 fields": {
+			input: &float64Test{Usagi: 20190122.5},
+			check: func(t *testing.T, got any) {
+				v := got.(*float64Test).Usagi
+				assert.True(t, 0 <= v && v < 100, v)
+				assert.Equal(t, v, math.Round(v*100)/100)
+			},
+		},
+		"float32 slice fields": {
+			input: &float32SliceTest{Usagi: []float32{1.5, 2.5, 1.5}},
+			check: func(t *testing.T, got any) {
+				v := got.(*float32SliceTest).Usagi
+				assert.Equal(t, v[0], v[2])
+				assert.NotEqual(t, v[0], v[1])
+			},
+		},
+		"string to int map fields": {
+			input: &stringToIntTest{Usagi: map[string]int{"うさぎ": 20190122, "うさぎ2": 20190122}},
+			check: func(t *testing.T, got any) {
+				v := got.(*stringToIntTest).Usagi
+				assert.Equal(t, v["うさぎ"], v["うさぎ2"])
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			SetPseudoKey([]byte("secret"))
+			got, err := Mask(tt.input)
+			if assert.NoError(t, err) {
+				tt.check(t, got)
+				got2, err := Mask(tt.input)
+				assert.NoError(t, err)
+				if diff := cmp.Diff(got, got2); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.SetPseudoKey([]byte("secret"))
+			got, err := m.Mask(tt.input)
+			if assert.NoError(t, err) {
+				tt.check(t, got)
+				got2, err := m.Mask(tt.input)
+				assert.NoError(t, err)
+				if diff := cmp.Diff(got, got2); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+
+	t.Run("different keys", func(t *testing.T) {
+		m1, m2 := newMasker(), newMasker()
+		m1.SetPseudoKey([]byte("secret"))
+		m2.SetPseudoKey([]byte("another secret"))
+		var same int
+		for i := 0; i < 10; i++ {
+			v1, err := m1.Int("pseudo1000000", i)
+			assert.NoError(t, err)
+			v2, err := m2.Int("pseudo1000000", i)
+			assert.NoError(t, err)
+			if v1 == v2 {
+				same++
+			}
+		}
+		assert.Less(t, same, 10)
+	})
+	t.Run("without a key", func(t *testing.T) {
+		m := newMasker()
+		_, err := m.Int("pseudo1000", 1)
+		assert.ErrorIs(t, err, ErrPseudoKeyRequired)
+		_, err = m.Float64("pseudo100.2", 1)
+		assert.ErrorIs(t, err, ErrPseudoKeyRequired)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		m := newMasker()
+		m.SetPseudoKey([]byte("secret"))
+		for _, tag := range []string{"pseudo", "pseudoabc", "pseudo0", "pseudo-1"} {
+			_, err := m.Int(tag, 1)
+			var argErr *InvalidArgError
+			assert.ErrorAs(t, err, &argErr, tag)
+		}
+		_, err := m.Float64("pseudoabc", 1)
+		var argErr *InvalidArgError
+		assert.ErrorAs(t, err, &argErr)
+	})
+}
+
 func TestMaskZero(t *testing.T) {
 	type stringTest struct {
 		Usagi string `mask:"zero"`
@@ -2675,6 +4050,316 @@ func TestAnyMaskFunc(t *testing.T) {
 	})
 }
 
+func TestRegisterMaskTypeFunc(t *testing.T) {
+	type Email string
+	type structTest struct {
+		Email   Email           `mask:"filled"`
+		Emails  []Email         `mask:"filled"`
+		ByID    map[int]Email   `mask:"filled"`
+		IP      net.IP          `mask:"ip"`
+		IPs     []net.IP        `mask:"ip"`
+		Time    time.Time       `mask:"zero"`
+		Any     any             `mask:"filled"`
+		AnyMap  map[string]any  `mask:"filled"`
+		Account string          `mask:"filled"`
+		Ptr     *Email          `mask:"filled"`
+		Named   map[Email]Email `mask:"filled"`
+	}
+	maskEmail := func(arg string, value any) (any, error) {
+		email := value.(Email)
+		i := strings.IndexByte(string(email), '@')
+		return Email(strings.Repeat("*", i)) + email[i:], nil
+	}
+	maskIP := func(arg string, value any) (any, error) {
+		ip := value.(net.IP)
+		if ip4 := ip.To4(); ip4 != nil {
+			return net.IPv4(ip4[0], ip4[1], 0, 0), nil
+		}
+		return ip.Mask(net.CIDRMask(64, 128)), nil
+	}
+	email := Email("usagi@example.com")
+	input := structTest{
+		Email:   email,
+		Emails:  []Email{email},
+		ByID:    map[int]Email{1: email},
+		IP:      net.ParseIP("192.168.1.10"),
+		IPs:     []net.IP{net.ParseIP("2001:db8::1")},
+		Time:    time.Date(2019, 1, 22, 0, 0, 0, 0, time.UTC),
+		Any:     email,
+		AnyMap:  map[string]any{"Usagi": email},
+		Account: "usagi@example.com",
+		Ptr:     &email,
+		Named:   map[Email]Email{email: email},
+	}
+	maskedEmail := Email("*****@example.com")
+	want := structTest{
+		Email:   maskedEmail,
+		Emails:  []Email{maskedEmail},
+		ByID:    map[int]Email{1: maskedEmail},
+		IP:      net.IPv4(192, 168, 0, 0),
+		IPs:     []net.IP{net.ParseIP("2001:db8::")},
+		Any:     maskedEmail,
+		AnyMap:  map[string]any{"Usagi": maskedEmail},
+		Account: "*****************",
+		Ptr:     &maskedEmail,
+		Named:   map[Email]Email{email: maskedEmail},
+	}
+
+	t.Run(defaultTestCase("named types"), func(t *testing.T) {
+		defer cleanup(t)
+		RegisterMaskTypeFunc(reflect.TypeOf(Email("")), MaskTypeFilled, maskEmail)
+		RegisterMaskTypeFunc(reflect.TypeOf(net.IP{}), "ip", maskIP)
+		got, err := Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("named types"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskTypeFunc(reflect.TypeOf(Email("")), MaskTypeFilled, maskEmail)
+		m.RegisterMaskTypeFunc(reflect.TypeOf(net.IP{}), "ip", maskIP)
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("other mask types"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskTypeFunc(reflect.TypeOf(Email("")), "domain", maskEmail)
+		got, err := m.String(MaskTypeFilled, string(email))
+		assert.NoError(t, err)
+		assert.Equal(t, "*****************", got)
+		got2, err := m.Mask(struct {
+			Email Email `mask:"fixed"`
+		}{Email: email})
+		assert.NoError(t, err)
+		assert.Equal(t, struct {
+			Email Email `mask:"fixed"`
+		}{Email: "********"}, got2)
+	})
+}
+
+func TestRegisterMaskType(t *testing.T) {
+	type Password string
+	type Secret struct {
+		Value string
+	}
+	type structTest struct {
+		Password    Password
+		Passwords   []Password
+		PtrMap      map[string]*Password
+		IntMap      map[int]Password
+		Interface   any
+		Secret      Secret
+		Tagged      Password `mask:"filled4"`
+		FieldName   Password
+		NotPassword string
+	}
+	password := Password("p@ssw0rd")
+	input := structTest{
+		Password:    password,
+		Passwords:   []Password{password},
+		PtrMap:      map[string]*Password{"Usagi": &password},
+		IntMap:      map[int]Password{1: password},
+		Interface:   password,
+		Secret:      Secret{Value: "secret"},
+		Tagged:      password,
+		FieldName:   password,
+		NotPassword: "p@ssw0rd",
+	}
+	fixed := Password("********")
+	want := structTest{
+		Password:    fixed,
+		Passwords:   []Password{fixed},
+		PtrMap:      map[string]*Password{"Usagi": &fixed},
+		IntMap:      map[int]Password{1: fixed},
+		Interface:   fixed,
+		Tagged:      "****",
+		FieldName:   "********",
+		NotPassword: "p@ssw0rd",
+	}
+
+	for _, policy := range []CopyPolicy{CopyDeep, CopyShare} {
+		t.Run(defaultTestCase(fmt.Sprintf("copy policy %d", policy)), func(t *testing.T) {
+			defer cleanup(t)
+			SetCopyPolicy(policy)
+			RegisterMaskType(reflect.TypeOf(password), MaskTypeFixed)
+			RegisterMaskType(reflect.TypeOf(Secret{}), MaskTypeZero)
+			RegisterMaskField("FieldName", MaskTypeFilled)
+			got, err := Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Error(diff)
+			}
+			assert.Equal(t, password, input.Password)
+		})
+		t.Run(newMaskerTestCase(fmt.Sprintf("copy policy %d", policy)), func(t *testing.T) {
+			m := newMasker()
+			m.SetCopyPolicy(policy)
+			m.RegisterMaskType(reflect.TypeOf(password), MaskTypeFixed)
+			m.RegisterMaskType(reflect.TypeOf(Secret{}), MaskTypeZero)
+			m.RegisterMaskField("FieldName", MaskTypeFilled)
+			got, err := m.Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run(newMaskerTestCase("top level"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskType(reflect.TypeOf(password), MaskTypeFixed)
+		got, err := m.Mask(password)
+		assert.NoError(t, err)
+		assert.Equal(t, fixed, got)
+	})
+
+	t.Run(newMaskerTestCase("validate"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskType(reflect.TypeOf(password), "fixd")
+		err := m.Validate(structTest{})
+		assert.ErrorIs(t, err, ErrUnregisteredMaskType)
+	})
+}
+
+// maskableCard masks itself, because reflection cannot mask its unexported number.
+type maskableCard struct {
+	number string
+	Holder string
+}
+
+func (c maskableCard) MaskWith(m *Masker, tag string) (any, error) {
+	number, err := m.String(MaskTypePartial+"(last=4)", c.number)
+	if err != nil {
+		return nil, err
+	}
+	holder, err := m.String(tag, c.Holder)
+	if err != nil {
+		return nil, err
+	}
+	return maskableCard{number: number, Holder: holder}, nil
+}
+
+// maskableToken implements Maskable with a pointer receiver.
+type maskableToken string
+
+func (t *maskableToken) MaskWith(m *Masker, tag string) (any, error) {
+	masked := maskableToken("token:" + strings.Repeat("*", len(*t)))
+	return &masked, nil
+}
+
+// maskableBroken returns a value of another type.
+type maskableBroken struct{}
+
+func (maskableBroken) MaskWith(m *Masker, tag string) (any, error) {
+	return "broken", nil
+}
+
+func TestMaskable(t *testing.T) {
+	type structTest struct {
+		Card      maskableCard `mask:"filled"`
+		NoTag     maskableCard
+		Cards     []maskableCard
+		CardMap   map[string]maskableCard
+		Interface any
+		Token     *maskableToken
+		NilToken  *maskableToken
+	}
+	token := maskableToken("secret")
+	card := maskableCard{number: "4111111111111111", Holder: "Usagi"}
+	input := structTest{
+		Card:      card,
+		NoTag:     card,
+		Cards:     []maskableCard{card},
+		CardMap:   map[string]maskableCard{"Usagi": card},
+		Interface: card,
+		Token:     &token,
+	}
+	maskedToken := maskableToken("token:******")
+	want := structTest{
+		Card:      maskableCard{number: "************1111", Holder: "*****"},
+		NoTag:     maskableCard{number: "************1111", Holder: "Usagi"},
+		Cards:     []maskableCard{{number: "************1111", Holder: "Usagi"}},
+		CardMap:   map[string]maskableCard{"Usagi": {number: "************1111", Holder: "Usagi"}},
+		Interface: maskableCard{number: "************1111", Holder: "Usagi"},
+		Token:     &maskedToken,
+	}
+
+	for _, policy := range []CopyPolicy{CopyDeep, CopyShare} {
+		t.Run(defaultTestCase(fmt.Sprintf("copy policy %d", policy)), func(t *testing.T) {
+			defer cleanup(t)
+			SetCopyPolicy(policy)
+			got, err := Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(want, got, cmp.AllowUnexported(maskableCard{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+		t.Run(newMaskerTestCase(fmt.Sprintf("copy policy %d", policy)), func(t *testing.T) {
+			m := newMasker()
+			m.SetCopyPolicy(policy)
+			got, err := m.Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(want, got, cmp.AllowUnexported(maskableCard{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run(newMaskerTestCase("error"), func(t *testing.T) {
+		m := newMasker()
+		_, err := m.Mask(struct {
+			Card maskableCard `mask:"partial(first=-1)"`
+		}{Card: card})
+		var argErr *InvalidArgError
+		assert.ErrorAs(t, err, &argErr)
+
+		_, err = m.Mask([]maskableBroken{{}})
+		assert.Error(t, err)
+	})
+
+	t.Run(newMaskerTestCase("validate"), func(t *testing.T) {
+		m := newMasker()
+		assert.NoError(t, m.Validate(struct {
+			Card maskableCard `mask:"custom"`
+		}{}))
+	})
+}
+
+func TestMask_NamedType(t *testing.T) {
+	type Email string
+	type Score float64
+	type structTest struct {
+		Any        any              `mask:"filled"`
+		IntKey     map[int]Email    `mask:"filled"`
+		StringKey  map[string]Email `mask:"filled"`
+		ScoreMap   map[string]Score `mask:"random10.1"`
+		InterfaceS []any            `mask:"filled"`
+	}
+	input := structTest{
+		Any:        Email("Usagi"),
+		IntKey:     map[int]Email{1: "Usagi"},
+		StringKey:  map[string]Email{"Usagi": "Usagi"},
+		ScoreMap:   map[string]Score{"Usagi": 0},
+		InterfaceS: []any{Email("Usagi")},
+	}
+
+	m := newMasker()
+	m.SetRand(rand.New(rand.NewSource(1)))
+	got, err := m.Mask(input)
+	assert.NoError(t, err)
+	result := got.(structTest)
+	assert.Equal(t, Email("*****"), result.Any)
+	assert.Equal(t, map[int]Email{1: "*****"}, result.IntKey)
+	assert.Equal(t, map[string]Email{"Usagi": "*****"}, result.StringKey)
+	assert.Len(t, result.ScoreMap, 1)
+	assert.Less(t, float64(result.ScoreMap["Usagi"]), 10.0)
+	assert.Equal(t, []any{Email("*****")}, result.InterfaceS)
+}
+
 func allowUnexported(v any) cmp.Options {
 	var options cmp.Options
 	if !reflect.ValueOf(v).IsValid() {
@@ -2740,10 +4425,164 @@ func newMaskerTestCase(name string) string {
 	return "newMasker:" + name
 }
 
+func TestSetFieldNameTag(t *testing.T) {
+	type Card struct {
+		Number string `json:"card_number,omitempty"`
+		Holder string `json:"holder"`
+		Secret string `json:"-"`
+		Dash   string `json:"-,"`
+		NoTag  string
+	}
+	type Payment struct {
+		Card  *Card
+		Extra map[string]any
+	}
+	input := Payment{
+		Card:  &Card{Number: "4111111111111111", Holder: "Usagi", Secret: "Chiikawa", Dash: "Hachiware", NoTag: "Momonga"},
+		Extra: map[string]any{"card_number": "4111111111111111", "Number": "1234"},
+	}
+
+	tests := map[string]struct {
+		fieldNameTag string
+		want         Payment
+	}{
+		"go name": {
+			fieldNameTag: "",
+			want: Payment{
+				Card:  &Card{Number: "4111111111111111", Holder: "*****", Secret: "Chiikawa", Dash: "Hachiware", NoTag: "Momonga"},
+				Extra: map[string]any{"card_number": "****************", "Number": "1234"},
+			},
+		},
+		"json name": {
+			fieldNameTag: "json",
+			want: Payment{
+				Card:  &Card{Number: "****************", Holder: "*****", Secret: "Chiikawa", Dash: "*********", NoTag: "Momonga"},
+				Extra: map[string]any{"card_number": "****************", "Number": "1234"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			RegisterMaskField("card_number", MaskTypeFilled)
+			RegisterMaskField("Holder", MaskTypeFilled)
+			RegisterMaskField("holder", MaskTypeFixed)
+			RegisterMaskField("-", MaskTypeFilled)
+			SetFieldNameTag(tt.fieldNameTag)
+			got, err := Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.RegisterMaskField("card_number", MaskTypeFilled)
+			m.RegisterMaskField("Holder", MaskTypeFilled)
+			m.RegisterMaskField("holder", MaskTypeFixed)
+			m.RegisterMaskField("-", MaskTypeFilled)
+			m.SetFieldNameTag(tt.fieldNameTag)
+			got, err := m.Mask(input)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run(newMaskerTestCase("set after masking"), func(t *testing.T) {
+		m := newMasker()
+		m.SetCopyPolicy(CopyShare)
+		m.RegisterMaskField("card_number", MaskTypeFilled)
+		card := Card{Number: "4111111111111111"}
+		got, err := m.Mask(card)
+		assert.NoError(t, err)
+		assert.Equal(t, card, got)
+
+		m.SetFieldNameTag("json")
+		got, err = m.Mask([]Card{card})
+		assert.NoError(t, err)
+		assert.Equal(t, []Card{{Number: "****************"}}, got)
+	})
+
+	t.Run(newMaskerTestCase("exact json name before rules"), func(t *testing.T) {
+		type Card struct {
+			CardNumber string `json:"card_number"`
+		}
+		m := newMasker()
+		m.SetFieldNameTag("json")
+		m.RegisterMaskField("card_number", MaskTypePartial+"(keep=4,from=end)")
+		assert.NoError(t, m.RegisterMaskFieldPattern("*Number", MaskTypeZero))
+		got, err := m.Mask(Card{CardNumber: "4111111111111111"})
+		assert.NoError(t, err)
+		assert.Equal(t, Card{CardNumber: "************1111"}, got)
+	})
+}
+
+func TestMaskField(t *testing.T) {
+	type User struct {
+		Name     string
+		Password string `mask:"fixed"`
+	}
+	tests := map[string]struct {
+		fieldName string
+		input     any
+		want      any
+	}{
+		"string":         {fieldName: "Password", input: "Usagi", want: "*****"},
+		"not registered": {fieldName: "Name", input: "Usagi", want: "Usagi"},
+		"slice":          {fieldName: "Password", input: []string{"Usagi", "Chiikawa"}, want: []string{"*****", "********"}},
+		"struct":         {fieldName: "User", input: User{Name: "Usagi", Password: "Usagi"}, want: User{Name: "Usagi", Password: "********"}},
+		"nil":            {fieldName: "Password", input: nil, want: nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			RegisterMaskField("Password", MaskTypeFilled)
+			got, err := MaskField(tt.fieldName, tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			m.RegisterMaskField("Password", MaskTypeFilled)
+			got, err := m.MaskField(tt.fieldName, tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run(newMaskerTestCase("unmatched"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskField("Password", MaskTypeFilled)
+		m.SetUnmatchedPolicy(UnmatchedFail)
+		_, err := m.MaskField("Password", true)
+		assert.ErrorIs(t, err, ErrIncompatibleMaskType)
+	})
+}
+
 func cleanup(t *testing.T) {
 	t.Helper()
-	defaultMasker.typeToStructCache.v = make(map[reflect.Type]structType)
+	defaultMasker.typeToStructCache.reset()
+	defaultMasker.Cache(true)
 	SetMaskChar(maskChar)
+	SetHashConfig(HashConfig{})
+	SetPseudoKey(nil)
+	SetRand(nil)
+	SetUnmatchedPolicy(UnmatchedKeep)
+	SetCopyPolicy(CopyDeep)
+	SetStructTagPolicy(StructTagIgnore)
+	SetBytesPolicy(BytesAsString)
+	SetFieldNameTag("")
+	defaultMasker.maskFieldMap = make(map[string]string)
+	defaultMasker.fieldRules = fieldRules{}
+	defaultMasker.maskTypeFuncs = make(map[reflect.Type]*funcRegistry[MaskAnyFunc])
+	defaultMasker.maskTypeMap = make(map[reflect.Type]string)
+	defaultMasker.maskWrappers = make(map[string]wrapperRule)
+	defaultMasker.registerSQLWrappers()
+	defaultMasker.jsonPaths = nil
 }
 
 func newMasker() *Masker {
@@ -2751,8 +4590,18 @@ func newMasker() *Masker {
 	m.RegisterMaskStringFunc(MaskTypeFilled, m.MaskFilledString)
 	m.RegisterMaskStringFunc(MaskTypeFixed, m.MaskFixedString)
 	m.RegisterMaskStringFunc(MaskTypeHash, m.MaskHashString)
+	m.RegisterMaskStringFunc(MaskTypePartial, m.MaskPartialString)
+	m.RegisterMaskStringFunc(MaskTypeEmail, m.MaskEmailString)
 	m.RegisterMaskIntFunc(MaskTypeRandom, m.MaskRandomInt)
+	m.RegisterMaskInt64Func(MaskTypeRandom, m.MaskRandomInt64)
+	m.RegisterMaskUint64Func(MaskTypeRandom, m.MaskRandomUint64)
+	m.RegisterMaskFloat32Func(MaskTypeRandom, m.MaskRandomFloat32)
 	m.RegisterMaskFloat64Func(MaskTypeRandom, m.MaskRandomFloat64)
+	m.RegisterMaskIntFunc(MaskTypePseudo, m.MaskPseudoInt)
+	m.RegisterMaskInt64Func(MaskTypePseudo, m.MaskPseudoInt64)
+	m.RegisterMaskUint64Func(MaskTypePseudo, m.MaskPseudoUint64)
+	m.RegisterMaskFloat32Func(MaskTypePseudo, m.MaskPseudoFloat32)
+	m.RegisterMaskFloat64Func(MaskTypePseudo, m.MaskPseudoFloat64)
 	m.RegisterMaskAnyFunc(MaskTypeZero, m.MaskZero)
 	return m
 }
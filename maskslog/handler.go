@@ -0,0 +1,123 @@
+//go:build go1.21
+
+// Package maskslog masks the attributes of log/slog records with go-mask.
+package maskslog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/showa-93/go-mask"
+)
+
+// Handler is a slog.Handler that masks the values of the attributes with a Masker
+// before passing them to the next handler.
+type Handler struct {
+	next   slog.Handler
+	masker *mask.Masker
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler that masks the attributes with the masker and passes them to next.
+// If m is nil, default masker is used.
+// The value of each attribute is masked by Masker.MaskField with the key of the attribute,
+// so the mask tag registered for the key by RegisterMaskField is applied to the value, as to a map key,
+// and the mask tags of structs passed to slog.Any are applied.
+// The attributes in groups are masked recursively with their own keys, and slog.LogValuer values are resolved before masking.
+// The value of Valuer is masked only once, by the masker of the Handler with the key.
+// If masking fails, the value is replaced with the error, so the value is never logged unmasked.
+func NewHandler(next slog.Handler, m *mask.Masker) *Handler {
+	return &Handler{next: next, masker: m}
+}
+
+// Enabled reports whether the next handler handles records at the level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle masks the attributes of the record and passes it to the next handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	masked := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		masked.AddAttrs(h.maskAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, masked)
+}
+
+// WithAttrs returns a Handler whose next handler has the masked attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		masked[i] = h.maskAttr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(masked), masker: h.masker}
+}
+
+// WithGroup returns a Handler whose next handler has the group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), masker: h.masker}
+}
+
+func (h *Handler) maskAttr(a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindLogValuer {
+		if lv, ok := a.Value.LogValuer().(valuer); ok {
+			// the raw value is masked once with the key, because masking the value masked by Valuer again
+			// changes the result of hash or random
+			a.Value = maskValue(h.masker, a.Key, slog.AnyValue(lv.v))
+			return a
+		}
+	}
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		masked := make([]slog.Attr, len(attrs))
+		for i, ga := range attrs {
+			masked[i] = h.maskAttr(ga)
+		}
+		a.Value = slog.GroupValue(masked...)
+	default:
+		a.Value = maskValue(h.masker, a.Key, a.Value)
+	}
+	return a
+}
+
+// Valuer returns a slog.LogValuer that logs the value masked by the masker,
+// so that the value can be passed to any handler without masking it at every call site.
+// If m is nil, default masker is used.
+// The value is masked when it is logged, not when Valuer is called.
+func Valuer(m *mask.Masker, v any) slog.LogValuer {
+	return valuer{masker: m, v: v}
+}
+
+type valuer struct {
+	masker *mask.Masker
+	v      any
+}
+
+func (lv valuer) LogValue() slog.Value {
+	return maskValue(lv.masker, "", slog.AnyValue(lv.v))
+}
+
+// maskValue masks the value of the attribute with the key,
+// or returns the error as the value if masking fails.
+func maskValue(m *mask.Masker, key string, v slog.Value) slog.Value {
+	if v.Kind() == slog.KindAny && v.Any() == nil {
+		return v
+	}
+	var (
+		masked any
+		err    error
+	)
+	if m == nil {
+		masked, err = mask.MaskField(key, v.Any())
+	} else {
+		masked, err = m.MaskField(key, v.Any())
+	}
+	if err != nil {
+		return slog.AnyValue(err)
+	}
+	return slog.AnyValue(masked)
+}
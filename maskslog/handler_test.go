@@ -0,0 +1,220 @@
+//go:build go1.21
+
+package maskslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/showa-93/go-mask"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name     string `mask:"filled"`
+	Password string `mask:"fixed"`
+	Age      int
+}
+
+type token string
+
+func (t token) LogValue() slog.Value {
+	return slog.AnyValue(user{Name: string(t)})
+}
+
+func newLogger(m *mask.Masker) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), m)), &buf
+}
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	delete(got, slog.TimeKey)
+	delete(got, slog.LevelKey)
+	delete(got, slog.MessageKey)
+	return got
+}
+
+func TestHandler(t *testing.T) {
+	input := user{Name: "Usagi", Password: "Chiikawa", Age: 3}
+	masked := map[string]any{"Name": "*****", "Password": "********", "Age": float64(3)}
+
+	tests := map[string]struct {
+		log  func(logger *slog.Logger)
+		want map[string]any
+	}{
+		"any": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", slog.Any("user", input))
+			},
+			want: map[string]any{"user": masked},
+		},
+		"pointer": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "user", &input)
+			},
+			want: map[string]any{"user": masked},
+		},
+		"group": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", slog.Group("request", slog.Any("user", input), slog.Int("id", 1)))
+			},
+			want: map[string]any{"request": map[string]any{"user": masked, "id": float64(1)}},
+		},
+		"log valuer": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "token", token("Hachiware"))
+			},
+			want: map[string]any{"token": map[string]any{"Name": "*********", "Password": "********", "Age": float64(0)}},
+		},
+		"with attrs": {
+			log: func(logger *slog.Logger) {
+				logger.With("user", input).WithGroup("request").Info("msg", "user", input)
+			},
+			want: map[string]any{"user": masked, "request": map[string]any{"user": masked}},
+		},
+		"scalar": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "name", "Usagi", "age", 3)
+			},
+			want: map[string]any{"name": "Usagi", "age": float64(3)},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, m := range []*mask.Masker{nil, newMasker()} {
+				logger, buf := newLogger(m)
+				tt.log(logger)
+				assert.Equal(t, tt.want, decode(t, buf))
+			}
+			assert.Equal(t, "Usagi", input.Name)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskStringFunc(mask.MaskTypeFilled, func(arg, value string) (string, error) {
+			return "", errors.New("fail")
+		})
+		logger, buf := newLogger(m)
+		logger.Info("msg", "user", input)
+		assert.Equal(t, map[string]any{"user": "fail"}, decode(t, buf))
+	})
+}
+
+func TestHandler_Key(t *testing.T) {
+	m := newMasker()
+	m.RegisterMaskField("password", mask.MaskTypeFixed)
+	m.RegisterMaskField("token", mask.MaskTypeFilled)
+	m.RegisterMaskField("Name", mask.MaskTypeFixed)
+
+	tests := map[string]struct {
+		log  func(logger *slog.Logger)
+		want map[string]any
+	}{
+		"string": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", slog.String("password", "Usagi"), slog.String("name", "Usagi"))
+			},
+			want: map[string]any{"password": "********", "name": "Usagi"},
+		},
+		"slice": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "token", []string{"Usagi", "Chiikawa"})
+			},
+			want: map[string]any{"token": []any{"*****", "********"}},
+		},
+		"group": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", slog.Group("request", "token", "Hachiware", "id", 1))
+			},
+			want: map[string]any{"request": map[string]any{"token": "*********", "id": float64(1)}},
+		},
+		"with attrs": {
+			log: func(logger *slog.Logger) {
+				logger.With("password", "Usagi").Info("msg")
+			},
+			want: map[string]any{"password": "********"},
+		},
+		"struct tag takes precedence": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "user", user{Name: "Usagi"})
+			},
+			want: map[string]any{"user": map[string]any{"Name": "*****", "Password": "********", "Age": float64(0)}},
+		},
+		"nil": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "token", nil)
+			},
+			want: map[string]any{"token": nil},
+		},
+		"unmatched": {
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "token", 1)
+			},
+			want: map[string]any{"token": float64(1)},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			logger, buf := newLogger(m)
+			tt.log(logger)
+			assert.Equal(t, tt.want, decode(t, buf))
+		})
+	}
+}
+
+func TestValuer(t *testing.T) {
+	input := user{Name: "Usagi", Password: "Chiikawa"}
+	want := map[string]any{"user": map[string]any{"Name": "*****", "Password": "********", "Age": float64(0)}}
+
+	for _, m := range []*mask.Masker{nil, newMasker()} {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		logger.Info("msg", "user", Valuer(m, input))
+		assert.Equal(t, want, decode(t, &buf))
+	}
+}
+
+func TestHandler_Valuer(t *testing.T) {
+	type hashed struct {
+		Name string `mask:"hash"`
+	}
+	input := hashed{Name: "Usagi"}
+
+	for _, m := range []*mask.Masker{nil, newMasker()} {
+		logger, buf := newLogger(m)
+		logger.Info("msg", "valuer", Valuer(m, input), "value", input)
+		got := decode(t, buf)
+		assert.NotEqual(t, "Usagi", got["value"].(map[string]any)["Name"])
+		assert.Equal(t, got["value"], got["valuer"])
+	}
+}
+
+func TestHandler_ValuerKey(t *testing.T) {
+	m := newMasker()
+	m.RegisterMaskField("password", mask.MaskTypeFilled)
+	logger, buf := newLogger(m)
+	logger.Info("msg", slog.Any("password", Valuer(m, "secret")), slog.String("password", "secret"))
+	// the record has the key twice, so it is checked without decoding
+	assert.Equal(t, 2, strings.Count(buf.String(), `"password":"******"`))
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func newMasker() *mask.Masker {
+	m := mask.NewMasker()
+	m.RegisterMaskStringFunc(mask.MaskTypeFilled, m.MaskFilledString)
+	m.RegisterMaskStringFunc(mask.MaskTypeFixed, m.MaskFixedString)
+	m.RegisterMaskStringFunc(mask.MaskTypeHash, m.MaskHashString)
+	return m
+}
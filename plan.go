@@ -0,0 +1,539 @@
+package mask
+
+import (
+	"reflect"
+	"sync"
+)
+
+// structPlan is the masking plan of a structure compiled from its type and the settings of the masker.
+// It is shared by concurrent calls, so it must not hold any value to be written.
+type structPlan struct {
+	// fields are the exported fields that need masking.
+	// The other fields are copied together with the private fields.
+	fields []fieldPlan
+	// untagged are the indexes of the exported fields without a tag,
+	// which are masked with the tag on the structure by StructTagPropagate.
+	untagged []int
+	// wrapper is set if the structure is registered by RegisterMaskWrapper.
+	wrapper *wrapper
+}
+
+// fieldOp is how a field is masked.
+type fieldOp int
+
+const (
+	// opMask masks the field with Masker.mask.
+	opMask fieldOp = iota
+	// opString masks the field with the resolved function for string.
+	opString
+	// opInt masks the field with the resolved function for the int kinds.
+	opInt
+	// opUint masks the field with the resolved function for the uint kinds.
+	opUint
+	// opFloat masks the field with the resolved function for the float kinds.
+	opFloat
+)
+
+// fieldPlan is the masking plan of an exported field.
+type fieldPlan struct {
+	index int
+	// tag is the mask tag of the field, or the tag registered for the field name.
+	tag string
+	valueFunc
+	// elem is the function resolved for the elements of a slice, an array or a map with string keys,
+	// so that the tag is not looked up for every element.
+	elem valueFunc
+}
+
+// valueFunc is the function of a tag resolved for the values of a type.
+type valueFunc struct {
+	op         fieldOp
+	arg        string
+	stringFunc MaskStringFunc
+	intFunc    intFunc
+	uintFunc   uintFunc
+	floatFunc  floatFunc
+}
+
+type typeToStructCache struct {
+	m sync.RWMutex
+	v map[reflect.Type]structPlan
+	// needsMask holds the results of Masker.needsMask.
+	needsMask map[reflect.Type]bool
+}
+
+func (c *typeToStructCache) get(rt reflect.Type) (structPlan, bool) {
+	defer c.m.RUnlock()
+	c.m.RLock()
+	plan, ok := c.v[rt]
+	return plan, ok
+}
+
+func (c *typeToStructCache) set(rt reflect.Type, plan structPlan) {
+	defer c.m.Unlock()
+	c.m.Lock()
+	c.v[rt] = plan
+}
+
+func (c *typeToStructCache) getNeedsMask(rt reflect.Type) (bool, bool) {
+	defer c.m.RUnlock()
+	c.m.RLock()
+	needs, ok := c.needsMask[rt]
+	return needs, ok
+}
+
+func (c *typeToStructCache) setNeedsMask(rt reflect.Type, needs bool) {
+	defer c.m.Unlock()
+	c.m.Lock()
+	c.needsMask[rt] = needs
+}
+
+// reset discards the compiled plans,
+// because the plans depend on the tag name, the field names, the functions registered and the copy policy of the masker.
+func (c *typeToStructCache) reset() {
+	defer c.m.Unlock()
+	c.m.Lock()
+	c.v = make(map[reflect.Type]structPlan)
+	c.needsMask = make(map[reflect.Type]bool)
+}
+
+// structPlan returns the masking plan of the structure, from the cache if caching is enabled.
+func (m *Masker) structPlan(rt reflect.Type) structPlan {
+	if m.cache {
+		if plan, ok := m.typeToStructCache.get(rt); ok {
+			return plan
+		}
+	}
+
+	var plan structPlan
+	plan.wrapper, _ = m.lookupWrapper(rt)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		// skip private field
+		if !field.IsExported() {
+			continue
+		}
+		fp, ok := m.compileField(i, field)
+		if ok {
+			plan.fields = append(plan.fields, fp)
+		}
+		if fp.tag == "" {
+			plan.untagged = append(plan.untagged, i)
+		}
+	}
+	if m.cache {
+		m.typeToStructCache.set(rt, plan)
+	}
+
+	return plan
+}
+
+// compileField resolves the function applied to the field.
+// It reports false if the field is copied without masking.
+func (m *Masker) compileField(index int, field reflect.StructField) (fieldPlan, bool) {
+	fp := fieldPlan{
+		index: index,
+		tag:   m.getFieldTag(field),
+	}
+	if fp.tag == "" {
+		fp.tag = m.getTypeTag(field.Type)
+	}
+
+	if isMaskable(field.Type) {
+		return fp, true
+	}
+
+	kind := field.Type.Kind()
+	switch kind {
+	case reflect.Interface, reflect.Ptr, reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		if fp.tag == "" && m.copyPolicy != CopyDeep && !m.needsMask(field.Type) {
+			// a struct or an array is copied by value together with the private fields
+			if m.copyPolicy == CopyShare || kind == reflect.Struct || kind == reflect.Array {
+				return fp, false
+			}
+		}
+		if fp.tag != "" && m.hasElems(field.Type, fp.tag) {
+			fp.elem = m.resolveFunc(fp.tag, field.Type.Elem())
+		}
+		// the values inside may be masked by their own tags
+		return fp, true
+	}
+	if fp.tag == "" {
+		return fp, false
+	}
+	fp.valueFunc = m.resolveFunc(fp.tag, field.Type)
+
+	return fp, true
+}
+
+// hasElems reports whether the tag is applied to each element of a value of the type rt,
+// which is a slice, an array or a map with string keys that no function for any type or for rt masks as a whole.
+func (m *Masker) hasElems(rt reflect.Type, tag string) bool {
+	switch rt.Kind() {
+	case reflect.Slice, reflect.Array:
+		if isBytes(rt) {
+			// masked according to the BytesPolicy
+			return false
+		}
+	case reflect.Map:
+		if rt.Key().Kind() != reflect.String {
+			return false
+		}
+	default:
+		return false
+	}
+	return !m.hasTypeFuncs(rt) && !m.maskAnyFuncs.has(tag)
+}
+
+// resolveFunc resolves the function of the tag for the values of the type rt.
+// The op of the result is opMask if the values are masked with Masker.mask.
+func (m *Masker) resolveFunc(tag string, rt reflect.Type) valueFunc {
+	var f valueFunc
+	if m.hasTypeFuncs(rt) || isMaskable(rt) {
+		return f
+	}
+
+	kind := rt.Kind()
+	switch kind {
+	case reflect.String:
+		// the functions for string take precedence over the functions for any type, as in Masker.String
+		if sf, arg, ok := m.maskStringFuncs.lookup(tag); ok {
+			f.op, f.stringFunc, f.arg = opString, sf, arg
+		}
+		return f
+	}
+	if m.maskAnyFuncs.has(tag) {
+		return f
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if intFunc, ok := m.lookupIntFunc(tag, rt); ok {
+			f.op, f.intFunc = opInt, intFunc
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if uintFunc, ok := m.lookupUintFunc(tag, rt); ok {
+			f.op, f.uintFunc = opUint, uintFunc
+		}
+	case reflect.Float32, reflect.Float64:
+		if floatFunc, ok := m.lookupFloatFunc(tag, kind); ok {
+			f.op, f.floatFunc = opFloat, floatFunc
+		}
+	}
+
+	return f
+}
+
+// apply masks rv into mp with the resolved function.
+// It must not be called if the op is opMask.
+func (f valueFunc) apply(rv, mp reflect.Value) error {
+	switch f.op {
+	case opString:
+		s, err := f.stringFunc(f.arg, rv.String())
+		if err != nil {
+			return err
+		}
+		mp.SetString(s)
+	case opInt:
+		v, err := f.intFunc.mask(rv)
+		if err != nil {
+			return err
+		}
+		mp.SetInt(v)
+	case opUint:
+		v, err := f.uintFunc.mask(rv)
+		if err != nil {
+			return err
+		}
+		mp.SetUint(v)
+	case opFloat:
+		v, err := f.floatFunc.mask(rv)
+		if err != nil {
+			return err
+		}
+		mp.SetFloat(v)
+	}
+
+	return nil
+}
+
+// maskField masks the field rv into mp according to the plan.
+func (m *Masker) maskField(field fieldPlan, rv, mp reflect.Value, cb circuitBreaker) error {
+	if field.op != opMask {
+		return field.apply(rv, mp)
+	}
+
+	var (
+		rvf reflect.Value
+		err error
+	)
+	if field.elem.op != opMask {
+		rvf, err = m.maskElems(rv, field.tag, field.elem, mp, cb)
+	} else {
+		rvf, err = m.mask(rv, field.tag, mp, cb)
+	}
+	if err != nil {
+		return err
+	}
+	mp.Set(rvf)
+
+	return nil
+}
+
+// maskElems masks a slice, an array or a map with string keys whose elements are masked with the resolved function.
+func (m *Masker) maskElems(rv reflect.Value, tag string, elem valueFunc, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
+	switch rv.Kind() {
+	case reflect.Map:
+		return m.maskMap(rv, tag, elem, mp, cb)
+	case reflect.Slice:
+		if rv.IsNil() {
+			return reflect.Zero(rv.Type()), nil
+		}
+	}
+	return m.maskSlice(rv, tag, elem, mp, cb)
+}
+
+// needsMask reports whether masking a value of the type without a tag may change the value.
+func (m *Masker) needsMask(rt reflect.Type) bool {
+	if m.cache {
+		if needs, ok := m.typeToStructCache.getNeedsMask(rt); ok {
+			return needs
+		}
+	}
+
+	// every type still being analyzed is in the type graph of rt, so the result for rt is final
+	needs, _ := m.analyzeType(rt, map[reflect.Type]bool{})
+	if m.cache {
+		m.typeToStructCache.setNeedsMask(rt, needs)
+	}
+
+	return needs
+}
+
+// analyzeType reports whether masking a value of the type without a tag may change the value.
+// final is false if the result depends on a type still being analyzed in a cycle.
+func (m *Masker) analyzeType(rt reflect.Type, visiting map[reflect.Type]bool) (needs, final bool) {
+	if m.cache {
+		if needs, ok := m.typeToStructCache.getNeedsMask(rt); ok {
+			return needs, true
+		}
+	}
+	if visiting[rt] {
+		return false, false
+	}
+
+	visiting[rt] = true
+	final = true
+	switch kind := rt.Kind(); {
+	case m.getTypeTag(rt) != "", isMaskable(rt):
+		// every value of the type is masked
+		needs = true
+	case kind == reflect.Interface:
+		// the dynamic type is analyzed when masking
+		needs = true
+	case kind == reflect.Ptr, kind == reflect.Slice, kind == reflect.Array:
+		needs, final = m.analyzeType(rt.Elem(), visiting)
+	case kind == reflect.Map:
+		if rt.Key().Kind() == reflect.String && m.hasFieldRules() {
+			// the keys may match the registered field names
+			needs = true
+			break
+		}
+		needs, final = m.analyzeType(rt.Elem(), visiting)
+	case kind == reflect.Struct:
+		for i := 0; i < rt.NumField() && !needs; i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if m.getFieldTag(field) != "" {
+				needs = true
+				break
+			}
+			n, f := m.analyzeType(field.Type, visiting)
+			needs, final = n, final && f
+		}
+	}
+	delete(visiting, rt)
+
+	// a type that needs masking does so regardless of the cycle
+	if needs || final {
+		if m.cache {
+			m.typeToStructCache.setNeedsMask(rt, needs)
+		}
+		return needs, true
+	}
+	return false, false
+}
+
+// intFunc is the function of a tag resolved for a value of the int kinds.
+type intFunc struct {
+	tag       string
+	arg       string
+	maskInt   MaskIntFunc
+	maskInt64 MaskInt64Func
+	// err is returned instead of calling the function if the range of the tag does not fit in the type.
+	err error
+}
+
+func (f intFunc) mask(rv reflect.Value) (int64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	var (
+		v   int64
+		err error
+	)
+	if f.maskInt != nil {
+		var i int
+		i, err = f.maskInt(f.arg, int(rv.Int()))
+		v = int64(i)
+	} else {
+		v, err = f.maskInt64(f.arg, rv.Int())
+	}
+	if err == nil && rv.OverflowInt(v) {
+		return 0, &OverflowError{Tag: f.tag, Value: v, Type: rv.Type()}
+	}
+	return v, err
+}
+
+// lookupIntFunc resolves the function of the tag for a value of the int type rt.
+// int values prefer the functions for int, and the other int kinds prefer the functions for int64.
+// The range of random and pseudo is checked against the type in advance,
+// and the results of the other functions are checked against the type of the value.
+func (m *Masker) lookupIntFunc(tag string, rt reflect.Type) (intFunc, bool) {
+	maskInt := func() (intFunc, bool) {
+		f, arg, ok := m.maskIntFuncs.lookup(tag)
+		return intFunc{tag: tag, arg: arg, maskInt: f}, ok
+	}
+	maskInt64 := func() (intFunc, bool) {
+		f, arg, ok := m.maskInt64Funcs.lookup(tag)
+		return intFunc{tag: tag, arg: arg, maskInt64: f}, ok
+	}
+
+	order := [2]func() (intFunc, bool){maskInt64, maskInt}
+	if rt.Kind() == reflect.Int {
+		order = [2]func() (intFunc, bool){maskInt, maskInt64}
+	}
+	for _, lookup := range order {
+		if f, ok := lookup(); ok {
+			if max, ok := rangeMax(tag, f.arg); ok && rt.Bits() < 64 && max > 1<<(rt.Bits()-1)-1 {
+				f.err = &OverflowError{Tag: tag, Value: max, Type: rt}
+			}
+			return f, true
+		}
+	}
+
+	return intFunc{}, false
+}
+
+// uintFunc is the function of a tag resolved for a value of the uint kinds.
+type uintFunc struct {
+	tag        string
+	arg        string
+	maskUint   MaskUintFunc
+	maskUint64 MaskUint64Func
+	// err is returned instead of calling the function if the range of the tag does not fit in the type.
+	err error
+}
+
+func (f uintFunc) mask(rv reflect.Value) (uint64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	var (
+		v   uint64
+		err error
+	)
+	if f.maskUint != nil {
+		var u uint
+		u, err = f.maskUint(f.arg, uint(rv.Uint()))
+		v = uint64(u)
+	} else {
+		v, err = f.maskUint64(f.arg, rv.Uint())
+	}
+	if err == nil && rv.OverflowUint(v) {
+		return 0, &OverflowError{Tag: f.tag, Value: v, Type: rv.Type()}
+	}
+	return v, err
+}
+
+// lookupUintFunc resolves the function of the tag for a value of the uint type rt.
+// uint values prefer the functions for uint, and the other uint kinds prefer the functions for uint64.
+// The range of random and pseudo is checked against the type in advance,
+// and the results of the other functions are checked against the type of the value.
+func (m *Masker) lookupUintFunc(tag string, rt reflect.Type) (uintFunc, bool) {
+	maskUint := func() (uintFunc, bool) {
+		f, arg, ok := m.maskUintFuncs.lookup(tag)
+		return uintFunc{tag: tag, arg: arg, maskUint: f}, ok
+	}
+	maskUint64 := func() (uintFunc, bool) {
+		f, arg, ok := m.maskUint64Funcs.lookup(tag)
+		return uintFunc{tag: tag, arg: arg, maskUint64: f}, ok
+	}
+
+	order := [2]func() (uintFunc, bool){maskUint64, maskUint}
+	if rt.Kind() == reflect.Uint {
+		order = [2]func() (uintFunc, bool){maskUint, maskUint64}
+	}
+	for _, lookup := range order {
+		if f, ok := lookup(); ok {
+			if max, ok := rangeMax(tag, f.arg); ok && rt.Bits() < 64 && uint64(max) > 1<<rt.Bits()-1 {
+				f.err = &OverflowError{Tag: tag, Value: max, Type: rt}
+			}
+			return f, true
+		}
+	}
+
+	return uintFunc{}, false
+}
+
+// rangeMax returns the largest number masked by the tag of random or pseudo with the argument.
+// It reports false for the other mask types and for an invalid argument, which the function reports by itself.
+func rangeMax(tag, arg string) (int64, bool) {
+	maskType := tag[:len(tag)-len(arg)]
+	if maskType != MaskTypeRandom && maskType != MaskTypePseudo {
+		return 0, false
+	}
+	n, err := parseIntRangeArg(maskType, arg)
+	if err != nil {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// floatFunc is the function of a tag resolved for a value of the float kinds.
+type floatFunc struct {
+	tag         string
+	arg         string
+	maskFloat32 MaskFloat32Func
+	maskFloat64 MaskFloat64Func
+}
+
+func (f floatFunc) mask(rv reflect.Value) (float64, error) {
+	if f.maskFloat32 != nil {
+		v, err := f.maskFloat32(f.arg, float32(rv.Float()))
+		return float64(v), err
+	}
+
+	v, err := f.maskFloat64(f.arg, rv.Float())
+	if err == nil && rv.OverflowFloat(v) {
+		return 0, &OverflowError{Tag: f.tag, Value: v, Type: rv.Type()}
+	}
+	return v, err
+}
+
+// lookupFloatFunc resolves the function of the tag for a value of the float kind k.
+// float32 values prefer the functions for float32.
+// The results of the functions for float64 are checked against the type of the value.
+func (m *Masker) lookupFloatFunc(tag string, k reflect.Kind) (floatFunc, bool) {
+	if k == reflect.Float32 {
+		if f, arg, ok := m.maskFloat32Funcs.lookup(tag); ok {
+			return floatFunc{tag: tag, arg: arg, maskFloat32: f}, true
+		}
+	}
+	if f, arg, ok := m.maskFloat64Funcs.lookup(tag); ok {
+		return floatFunc{tag: tag, arg: arg, maskFloat64: f}, true
+	}
+
+	return floatFunc{}, false
+}
@@ -0,0 +1,94 @@
+package mask
+
+import "strings"
+
+// funcRegistry holds the mask functions for a type of value by mask type.
+// The mask types are kept in a trie of their bytes so that the longest mask type
+// that is a prefix of a tag is found in a single walk of the tag.
+type funcRegistry[F any] struct {
+	root funcNode[F]
+}
+
+type funcNode[F any] struct {
+	label    byte
+	children []*funcNode[F]
+	f        F
+	ok       bool
+}
+
+func (n *funcNode[F]) child(c byte) *funcNode[F] {
+	for _, child := range n.children {
+		if child.label == c {
+			return child
+		}
+	}
+	return nil
+}
+
+// registry is implemented by a funcRegistry of any function type.
+type registry interface {
+	has(tag string) bool
+}
+
+func newFuncRegistry[F any]() *funcRegistry[F] {
+	return &funcRegistry[F]{}
+}
+
+func (r *funcRegistry[F]) register(maskType string, f F) {
+	n := &r.root
+	for i := 0; i < len(maskType); i++ {
+		child := n.child(maskType[i])
+		if child == nil {
+			child = &funcNode[F]{label: maskType[i]}
+			n.children = append(n.children, child)
+		}
+		n = child
+	}
+	n.f, n.ok = f, true
+}
+
+// lookup returns the function of the mask type in the tag and the argument for it.
+// A tag like "name(args)" is looked up by the exact mask type first.
+// Otherwise the longest mask type that is a prefix of the tag is used, and the rest of the tag is the argument.
+// The cost does not depend on the number of registered functions.
+func (r *funcRegistry[F]) lookup(tag string) (F, string, bool) {
+	if i := strings.IndexByte(tag, '('); i > 0 && isStructuredArg(tag[i:]) {
+		if n := r.find(tag[:i]); n != nil && n.ok {
+			return n.f, tag[i:], true
+		}
+	}
+
+	var match *funcNode[F]
+	var size int
+	n := &r.root
+	for i := 0; ; i++ {
+		if n.ok {
+			match, size = n, i
+		}
+		if i == len(tag) {
+			break
+		}
+		if n = n.child(tag[i]); n == nil {
+			break
+		}
+	}
+	if match == nil {
+		var f F
+		return f, "", false
+	}
+	return match.f, tag[size:], true
+}
+
+// find returns the node of the mask type, or nil if no mask type starts with it.
+func (r *funcRegistry[F]) find(maskType string) *funcNode[F] {
+	n := &r.root
+	for i := 0; i < len(maskType) && n != nil; i++ {
+		n = n.child(maskType[i])
+	}
+	return n
+}
+
+func (r *funcRegistry[F]) has(tag string) bool {
+	_, _, ok := r.lookup(tag)
+	return ok
+}
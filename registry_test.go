@@ -0,0 +1,128 @@
+package mask
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncRegistry_Lookup(t *testing.T) {
+	tests := map[string]struct {
+		maskTypes []string
+		tag       string
+		want      string
+		wantArg   string
+		ok        bool
+	}{
+		"exact": {
+			maskTypes: []string{"hash"},
+			tag:       "hash",
+			want:      "hash",
+			ok:        true,
+		},
+		"prefix": {
+			maskTypes: []string{"random"},
+			tag:       "random100",
+			want:      "random",
+			wantArg:   "100",
+			ok:        true,
+		},
+		"longest prefix registered later": {
+			maskTypes: []string{"hash", "hashv2"},
+			tag:       "hashv2",
+			want:      "hashv2",
+			ok:        true,
+		},
+		"longest prefix registered first": {
+			maskTypes: []string{"hashv2", "hash"},
+			tag:       "hashv2",
+			want:      "hashv2",
+			ok:        true,
+		},
+		"shorter prefix": {
+			maskTypes: []string{"hash", "hashv2"},
+			tag:       "hashv",
+			want:      "hash",
+			wantArg:   "v",
+			ok:        true,
+		},
+		"structured argument": {
+			maskTypes: []string{"random", "random1"},
+			tag:       "random(100)",
+			want:      "random",
+			wantArg:   "(100)",
+			ok:        true,
+		},
+		"structured argument of a registered prefix": {
+			maskTypes: []string{"random1", "random"},
+			tag:       "random1(00)",
+			want:      "random1",
+			wantArg:   "(00)",
+			ok:        true,
+		},
+		"legacy argument in parentheses": {
+			maskTypes: []string{"regexp"},
+			tag:       "regexp(最高).",
+			want:      "regexp",
+			wantArg:   "(最高).",
+			ok:        true,
+		},
+		"empty mask type": {
+			maskTypes: []string{""},
+			tag:       "anything",
+			want:      "",
+			wantArg:   "anything",
+			ok:        true,
+		},
+		"not registered": {
+			maskTypes: []string{"hashv2"},
+			tag:       "hash",
+		},
+		"no common prefix": {
+			maskTypes: []string{"hash", "filled"},
+			tag:       "random1000",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newFuncRegistry[string]()
+			for _, mt := range tt.maskTypes {
+				r.register(mt, mt)
+			}
+			got, arg, ok := r.lookup(tt.tag)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantArg, arg)
+		})
+	}
+}
+
+func BenchmarkFuncRegistry_Lookup(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("registered=%d", n), func(b *testing.B) {
+			r := newFuncRegistry[MaskStringFunc]()
+			for i := 0; i < n; i++ {
+				r.register(fmt.Sprintf("custom%03d", i), nil)
+			}
+			r.register(MaskTypeFilled, nil)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.lookup("filled4")
+			}
+		})
+		b.Run(fmt.Sprintf("registered=%d miss", n), func(b *testing.B) {
+			r := newFuncRegistry[MaskStringFunc]()
+			for i := 0; i < n; i++ {
+				r.register(fmt.Sprintf("custom%03d", i), nil)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.lookup("random1000.3")
+			}
+		})
+	}
+}
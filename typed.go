@@ -0,0 +1,53 @@
+package mask
+
+import "reflect"
+
+// TypedMasker masks values of the type T.
+type TypedMasker[T any] struct {
+	masker *Masker
+}
+
+// For returns a TypedMasker that masks values of the type T
+// with default masker.
+func For[T any]() *TypedMasker[T] {
+	return ForMasker[T](defaultMasker)
+}
+
+// ForMasker returns a TypedMasker that masks values of the type T with the masker.
+// If T is a struct, its masking plan is compiled here instead of on the first call of Mask.
+func ForMasker[T any](m *Masker) *TypedMasker[T] {
+	if rt := typeOf[T](); rt.Kind() == reflect.Struct {
+		m.structPlan(rt)
+	}
+	return &TypedMasker[T]{masker: m}
+}
+
+// Mask returns a copy of the target with the mask applied.
+// Unlike the package-level Mask, the result is written to a value of T directly
+// instead of being converted to any and asserted back to T.
+func (tm *TypedMasker[T]) Mask(target T) (ret T, err error) {
+	mp := reflect.ValueOf(&ret).Elem()
+	rv, err := tm.masker.mask(reflect.ValueOf(&target).Elem(), "", mp, localCircuitBreaker{})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	mp.Set(rv)
+
+	return ret, nil
+}
+
+// RegisterTypeFunc registers a masking function for the values of the type T.
+// The function is applied when the mask type is assigned as a tag to a value of exactly the type T,
+// in preference to the functions for the kind of the value and the functions for any type.
+func RegisterTypeFunc[T any](m *Masker, maskType string, maskFunc func(arg string, value T) (T, error)) {
+	m.RegisterMaskTypeFunc(typeOf[T](), maskType, func(arg string, value any) (any, error) {
+		v, err := maskFunc(arg, value.(T))
+		return v, err
+	})
+}
+
+// typeOf returns the type T, even if T is an interface type.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
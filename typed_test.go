@@ -0,0 +1,207 @@
+package mask
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedMasker_Mask(t *testing.T) {
+	type Address struct {
+		PostCode string `mask:"filled"`
+	}
+	type User struct {
+		Name    string `mask:"fixed"`
+		Age     int
+		Address *Address
+		Tags    []string `mask:"filled"`
+		private string
+	}
+	input := User{
+		Name:    "Usagi",
+		Age:     3,
+		Address: &Address{PostCode: "123-4567"},
+		Tags:    []string{"Chiikawa"},
+		private: "Hachiware",
+	}
+	want := User{
+		Name:    "********",
+		Age:     3,
+		Address: &Address{PostCode: "********"},
+		Tags:    []string{"********"},
+		private: "Hachiware",
+	}
+
+	t.Run(defaultTestCase("struct"), func(t *testing.T) {
+		defer cleanup(t)
+		got, err := For[User]().Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got, allowUnexported(input)); diff != "" {
+			t.Error(diff)
+		}
+		assert.Equal(t, "123-4567", input.Address.PostCode)
+	})
+	t.Run(newMaskerTestCase("struct"), func(t *testing.T) {
+		got, err := ForMasker[User](newMasker()).Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got, allowUnexported(input)); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("pointer"), func(t *testing.T) {
+		got, err := ForMasker[*User](newMasker()).Mask(&input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(&want, got, allowUnexported(input)); diff != "" {
+			t.Error(diff)
+		}
+		assert.NotSame(t, &input, got)
+	})
+	t.Run(newMaskerTestCase("nil pointer"), func(t *testing.T) {
+		got, err := ForMasker[*User](newMasker()).Mask(nil)
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+	})
+	t.Run(newMaskerTestCase("slice"), func(t *testing.T) {
+		got, err := ForMasker[[]User](newMasker()).Mask([]User{input})
+		assert.NoError(t, err)
+		if diff := cmp.Diff([]User{want}, got, allowUnexported(input)); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("map"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskField("Usagi", MaskTypeFilled)
+		got, err := ForMasker[map[string]string](m).Mask(map[string]string{"Usagi": "Usagi", "Chiikawa": "Chiikawa"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"Usagi": "*****", "Chiikawa": "Chiikawa"}, got)
+	})
+	t.Run(newMaskerTestCase("interface"), func(t *testing.T) {
+		got, err := ForMasker[any](newMasker()).Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got, allowUnexported(input)); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("error"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskStringFunc(MaskTypeFilled, func(arg, value string) (string, error) {
+			return "", errors.New("fail")
+		})
+		got, err := ForMasker[User](m).Mask(User{Name: "Usagi", Tags: []string{"Chiikawa"}})
+		assert.Error(t, err)
+		assert.Equal(t, User{}, got)
+	})
+}
+
+func TestRegisterTypeFunc(t *testing.T) {
+	type Email string
+	type structTest struct {
+		Date      time.Time            `mask:"date"`
+		DatePtr   *time.Time           `mask:"date"`
+		Email     Email                `mask:"filled"`
+		Emails    []Email              `mask:"filled"`
+		EmailMap  map[string]Email     `mask:"filled"`
+		String    string               `mask:"filled"`
+		Interface any                  `mask:"filled"`
+		Times     map[string]time.Time `mask:"date"`
+	}
+	maskDate := func(arg string, value time.Time) (time.Time, error) {
+		return value.Truncate(24 * time.Hour), nil
+	}
+	maskEmail := func(arg string, value Email) (Email, error) {
+		i := strings.IndexByte(string(value), '@')
+		if i < 0 {
+			return Email(strings.Repeat("*", len(value))), nil
+		}
+		return Email(strings.Repeat("*", i)) + value[i:], nil
+	}
+	date := time.Date(2019, 1, 22, 12, 34, 56, 0, time.UTC)
+	input := structTest{
+		Date:      date,
+		DatePtr:   &date,
+		Email:     "usagi@example.com",
+		Emails:    []Email{"chiikawa@example.com"},
+		EmailMap:  map[string]Email{"Hachiware": "hachiware@example.com"},
+		String:    "usagi@example.com",
+		Interface: Email("momonga@example.com"),
+		Times:     map[string]time.Time{"Usagi": date},
+	}
+	truncated := time.Date(2019, 1, 22, 0, 0, 0, 0, time.UTC)
+	want := structTest{
+		Date:      truncated,
+		DatePtr:   &truncated,
+		Email:     "*****@example.com",
+		Emails:    []Email{"********@example.com"},
+		EmailMap:  map[string]Email{"Hachiware": "*********@example.com"},
+		String:    "*****************",
+		Interface: Email("*******@example.com"),
+		Times:     map[string]time.Time{"Usagi": truncated},
+	}
+
+	for _, cache := range []bool{true, false} {
+		m := newMasker()
+		m.Cache(cache)
+		RegisterTypeFunc(m, "date", maskDate)
+		RegisterTypeFunc(m, MaskTypeFilled, maskEmail)
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+		assert.NoError(t, m.Validate(structTest{}))
+	}
+
+	t.Run(newMaskerTestCase("registered after masking"), func(t *testing.T) {
+		type emailTest struct {
+			Email Email `mask:"filled"`
+		}
+		m := newMasker()
+		got, err := m.Mask(emailTest{Email: input.Email})
+		assert.NoError(t, err)
+		assert.Equal(t, emailTest{Email: "*****************"}, got)
+		RegisterTypeFunc(m, MaskTypeFilled, maskEmail)
+		got, err = m.Mask(emailTest{Email: input.Email})
+		assert.NoError(t, err)
+		assert.Equal(t, emailTest{Email: want.Email}, got)
+	})
+
+	t.Run(newMaskerTestCase("validate"), func(t *testing.T) {
+		m := newMasker()
+		RegisterTypeFunc(m, "date", maskDate)
+		err := m.Validate(struct {
+			Date time.Time `mask:"date"`
+			Name string    `mask:"date"`
+		}{})
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr) && assert.Len(t, validationErr.Errors, 1) {
+			assert.ErrorIs(t, validationErr.Errors[0], ErrIncompatibleMaskType)
+		}
+	})
+}
+
+func BenchmarkTypedMasker_Mask(b *testing.B) {
+	type User struct {
+		ID    int
+		Name  string `mask:"fixed"`
+		Email string `mask:"email"`
+	}
+	input := User{ID: 1, Name: "Usagi", Email: "usagi@example.com"}
+
+	b.Run("Mask", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			Mask(input)
+		}
+	})
+	b.Run("TypedMasker", func(b *testing.B) {
+		tm := For[User]()
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			tm.Mask(input)
+		}
+	})
+}
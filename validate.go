@@ -0,0 +1,191 @@
+package mask
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Validate reports every mask tag in the type of the target that cannot be applied
+// from default masker.
+func Validate(target any) error {
+	return defaultMasker.Validate(target)
+}
+
+// Validate walks the type of the target, including the types of pointers, slices, arrays, maps and nested structs,
+// and reports every mask tag that references an unregistered mask type, a mask type that has no function for the kind of the field,
+// an argument that the mask function rejects, or a range of random or pseudo that does not fit in the type of the field.
+// The tags registered for the names of the fields by RegisterMaskField and the field name rules,
+// and for the types of the fields by RegisterMaskType are also checked.
+// The target can be a value or a reflect.Type.
+// The mask functions are called with the zero value of the field to check their arguments.
+// If any tag cannot be applied, a *ValidationError is returned.
+func (m *Masker) Validate(target any) error {
+	rt, ok := target.(reflect.Type)
+	if !ok {
+		rt = reflect.TypeOf(target)
+	}
+	if rt == nil {
+		return nil
+	}
+
+	var errs []*TagError
+	m.validateType(rt, rt.String(), map[reflect.Type]bool{}, &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+func (m *Masker) validateType(rt reflect.Type, path string, visited map[reflect.Type]bool, errs *[]*TagError) {
+	switch rt.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		m.validateType(rt.Elem(), path, visited, errs)
+	case reflect.Struct:
+		if visited[rt] {
+			return
+		}
+		visited[rt] = true
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := path + "." + field.Name
+			tag := m.getFieldTag(field)
+			if tag == "" {
+				tag = m.getTypeTag(field.Type)
+			}
+			if tag != "" {
+				if err := m.validateTag(field.Type, tag, nil); err != nil {
+					*errs = append(*errs, &TagError{Path: fieldPath, Tag: tag, Err: err})
+				}
+			}
+			m.validateType(field.Type, fieldPath, visited, errs)
+		}
+	}
+}
+
+// validateTag checks that the tag can be applied to a value of the type.
+// visiting holds the struct types whose fields the tag is propagated to, to stop at a recursive type.
+func (m *Masker) validateTag(rt reflect.Type, tag string, visiting map[reflect.Type]bool) error {
+	if isMaskable(rt) {
+		// the type interprets the tag by itself
+		return nil
+	}
+	if !m.isRegisteredMaskType(tag) {
+		return ErrUnregisteredMaskType
+	}
+
+	if rt.Kind() == reflect.Interface {
+		// the type of the value is not known until masking
+		return nil
+	}
+	if w := m.wrapperFor(rt, tag); w != nil {
+		return m.validateTag(rt.Field(w.value).Type, tag, visiting)
+	}
+	if f, arg, ok := m.lookupAnyFunc(tag, rt); ok {
+		_, err := f(arg, reflect.Zero(rt).Interface())
+		return err
+	}
+
+	if isBytes(rt) {
+		switch m.bytesPolicy {
+		case BytesAsString:
+			if f, arg, ok := m.maskStringFuncs.lookup(tag); ok {
+				_, err := f(arg, "")
+				return err
+			}
+		case BytesZero:
+			return nil
+		}
+	}
+
+	switch rt.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return m.validateTag(rt.Elem(), tag, visiting)
+	case reflect.Struct:
+		plan := m.structPlan(rt)
+		if m.structTagPolicy != StructTagPropagate || len(plan.untagged)+len(plan.fields) == 0 {
+			break
+		}
+		if visiting[rt] {
+			return nil
+		}
+		if visiting == nil {
+			visiting = map[reflect.Type]bool{}
+		}
+		visiting[rt] = true
+		for _, index := range plan.untagged {
+			if err := m.validateTag(rt.Field(index).Type, tag, visiting); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.String:
+		if f, arg, ok := m.maskStringFuncs.lookup(tag); ok {
+			_, err := f(arg, "")
+			return err
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f, ok := m.lookupIntFunc(tag, rt); ok && f.err != nil {
+			// the range of the tag does not fit in the type, so every value fails
+			return f.err
+		}
+		if hasFunc(tag, m.maskIntFuncs, m.maskInt64Funcs) {
+			_, err := m.intValue(tag, reflect.Zero(rt))
+			return ignoreOverflow(err)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if f, ok := m.lookupUintFunc(tag, rt); ok && f.err != nil {
+			// the range of the tag does not fit in the type, so every value fails
+			return f.err
+		}
+		if hasFunc(tag, m.maskUintFuncs, m.maskUint64Funcs) {
+			_, err := m.uintValue(tag, reflect.Zero(rt))
+			return ignoreOverflow(err)
+		}
+	case reflect.Float32, reflect.Float64:
+		if hasFunc(tag, m.maskFloat32Funcs, m.maskFloat64Funcs) {
+			_, err := m.floatValue(tag, reflect.Zero(rt))
+			return ignoreOverflow(err)
+		}
+	}
+
+	return ErrIncompatibleMaskType
+}
+
+// ignoreOverflow drops an OverflowError of the result of a function,
+// because whether the masked zero value overflows says nothing about the other values.
+// The range of random and pseudo that does not fit in the type is reported before masking the zero value.
+func ignoreOverflow(err error) error {
+	var overflowErr *OverflowError
+	if errors.As(err, &overflowErr) {
+		return nil
+	}
+	return err
+}
+
+// isRegisteredMaskType reports whether a function for any kind is registered for the mask type in the tag.
+func (m *Masker) isRegisteredMaskType(tag string) bool {
+	if hasFunc(tag, m.maskStringFuncs, m.maskIntFuncs, m.maskInt64Funcs, m.maskUintFuncs, m.maskUint64Funcs,
+		m.maskFloat32Funcs, m.maskFloat64Funcs, m.maskAnyFuncs) {
+		return true
+	}
+	for _, r := range m.maskTypeFuncs {
+		if r.has(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFunc(tag string, registries ...registry) bool {
+	for _, r := range registries {
+		if r.has(tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	type Address struct {
+		PostCode string `mask:"zero"`
+		City     string `mask:"fillde"`
+	}
+	type Node struct {
+		Value string `mask:"filled"`
+		Next  *Node
+	}
+	type Valid struct {
+		Name     string            `mask:"filled4"`
+		Email    string            `mask:"email(keep=1)"`
+		Age      int               `mask:"random100"`
+		Score    float32           `mask:"random100.2"`
+		IDs      []uint64          `mask:"random1000"`
+		Tags     map[string]string `mask:"hash"`
+		Any      any               `mask:"filled"`
+		Ptr      *string           `mask:"fixed"`
+		Time     time.Time         `mask:"zero"`
+		Node     *Node
+		NoTag    string
+		private  string `mask:"unknown"`
+		Children []Valid
+	}
+	type Invalid struct {
+		Unknown  string            `mask:"unknown"`
+		Filled   int               `mask:"filled"`
+		Random   int               `mask:"randomabc"`
+		Partial  []string          `mask:"partial1,2,3"`
+		Struct   time.Time         `mask:"filled"`
+		Bool     bool              `mask:"random10"`
+		Map      map[string]*int64 `mask:"random"`
+		Int8     int8              `mask:"random1000"`
+		Uint16s  []uint16          `mask:"pseudo(max=70000)"`
+		Address  Address
+		Children []*Invalid
+	}
+
+	tests := map[string]struct {
+		target any
+		want   map[string]error
+	}{
+		"nil": {
+			target: nil,
+		},
+		"valid": {
+			target: Valid{},
+		},
+		"valid pointer": {
+			target: &Valid{},
+		},
+		"valid reflect.Type": {
+			target: reflect.TypeOf(Valid{}),
+		},
+		"invalid": {
+			target: []Invalid{},
+			want: map[string]error{
+				"[]mask.Invalid.Unknown":      ErrUnregisteredMaskType,
+				"[]mask.Invalid.Filled":       ErrIncompatibleMaskType,
+				"[]mask.Invalid.Random":       &InvalidArgError{},
+				"[]mask.Invalid.Partial":      &InvalidArgError{},
+				"[]mask.Invalid.Struct":       ErrIncompatibleMaskType,
+				"[]mask.Invalid.Bool":         ErrIncompatibleMaskType,
+				"[]mask.Invalid.Map":          &InvalidArgError{},
+				"[]mask.Invalid.Int8":         &OverflowError{},
+				"[]mask.Invalid.Uint16s":      &OverflowError{},
+				"[]mask.Invalid.Address.City": ErrUnregisteredMaskType,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(defaultTestCase(name), func(t *testing.T) {
+			defer cleanup(t)
+			assertValidationError(t, tt.want, Validate(tt.target))
+		})
+		t.Run(newMaskerTestCase(name), func(t *testing.T) {
+			m := newMasker()
+			assertValidationError(t, tt.want, m.Validate(tt.target))
+		})
+	}
+
+	t.Run("large partial counts", func(t *testing.T) {
+		rt := reflect.StructOf([]reflect.StructField{{
+			Name: "Partial",
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`mask:"partial%d,%d"`, math.MaxInt, math.MaxInt)),
+		}})
+		assert.NoError(t, newMasker().Validate(rt))
+	})
+
+	t.Run("custom tag name", func(t *testing.T) {
+		m := newMasker()
+		m.SetTagName("fake")
+		assert.NoError(t, m.Validate(Invalid{}))
+	})
+
+	t.Run("field name", func(t *testing.T) {
+		type User struct {
+			Password string
+			Token    string `json:"access_token"`
+			Name     string `json:"-"`
+		}
+		m := newMasker()
+		m.SetFieldNameTag("json")
+		m.RegisterMaskField("Password", "filed")
+		m.RegisterMaskFieldFold("ACCESS_TOKEN", "fixd")
+		m.RegisterMaskField("Name", MaskTypeFilled)
+		assertValidationError(t, map[string]error{
+			"mask.User.Password": ErrUnregisteredMaskType,
+			"mask.User.Token":    ErrUnregisteredMaskType,
+		}, m.Validate(User{}))
+	})
+}
+
+func assertValidationError(t *testing.T, want map[string]error, err error) {
+	t.Helper()
+	if len(want) == 0 {
+		assert.NoError(t, err)
+		return
+	}
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("want a ValidationError, got %v", err)
+	}
+	got := make(map[string]error, len(validationErr.Errors))
+	for _, tagErr := range validationErr.Errors {
+		got[tagErr.Path] = tagErr.Err
+	}
+	assert.Len(t, got, len(want))
+	for path, wantErr := range want {
+		gotErr, ok := got[path]
+		if !ok {
+			t.Errorf("%s: want an error", path)
+			continue
+		}
+		if argErr := (*InvalidArgError)(nil); errors.As(wantErr, &argErr) {
+			assert.ErrorAs(t, gotErr, &argErr, path)
+			continue
+		}
+		if overflowErr := (*OverflowError)(nil); errors.As(wantErr, &overflowErr) {
+			assert.ErrorAs(t, gotErr, &overflowErr, path)
+			continue
+		}
+		assert.ErrorIs(t, gotErr, wantErr, path)
+	}
+	assert.ErrorIs(t, err, want[validationErr.Errors[0].Path])
+}
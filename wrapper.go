@@ -0,0 +1,117 @@
+package mask
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+// wrapperRule names the fields of a wrapper type registered by RegisterMaskWrapper.
+type wrapperRule struct {
+	valueField string
+	validField string
+}
+
+// wrapper is a wrapperRule resolved for a struct type.
+type wrapper struct {
+	value int
+	// valid is -1 if the wrapper has no valid field.
+	valid int
+}
+
+// registerSQLWrappers registers the nullable types of database/sql.
+func (m *Masker) registerSQLWrappers() {
+	m.RegisterMaskWrapper(reflect.TypeOf(sql.NullString{}), "String", "Valid")
+	m.RegisterMaskWrapper(reflect.TypeOf(sql.NullInt64{}), "Int64", "Valid")
+	m.RegisterMaskWrapper(reflect.TypeOf(sql.NullInt32{}), "Int32", "Valid")
+	m.RegisterMaskWrapper(reflect.TypeOf(sql.NullInt16{}), "Int16", "Valid")
+	m.RegisterMaskWrapper(reflect.TypeOf(sql.NullByte{}), "Byte", "Valid")
+	m.RegisterMaskWrapper(reflect.TypeOf(sql.NullFloat64{}), "Float64", "Valid")
+	m.RegisterMaskWrapper(reflect.TypeOf(sql.NullBool{}), "Bool", "Valid")
+	m.RegisterMaskWrapper(reflect.TypeOf(sql.NullTime{}), "Time", "Valid")
+	// sql.Null[T] is added in Go 1.22
+	m.maskWrappers["database/sql.Null"] = wrapperRule{valueField: "V", validField: "Valid"}
+}
+
+// RegisterMaskWrapper registers a struct type that wraps a value, like sql.NullString or a generic Optional[T].
+// A mask tag on a value of the type is applied to the field named valueField instead of the struct,
+// and the other fields are copied as they are.
+// If validField is not empty, it names a bool field, and the wrapped value is masked only when the field is true.
+// When the field is false, the wrapped value is replaced with the zero value so that an inconsistent wrapper does not leak it.
+// Registering an instantiation of a generic type registers every instantiation of it.
+// The nullable types of database/sql, including sql.Null[T], are registered by default.
+func (m *Masker) RegisterMaskWrapper(rt reflect.Type, valueField, validField string) {
+	m.maskWrappers[genericTypeName(rt)] = wrapperRule{valueField: valueField, validField: validField}
+	m.typeToStructCache.reset()
+}
+
+// lookupWrapper resolves the wrapper registered for the struct type.
+func (m *Masker) lookupWrapper(rt reflect.Type) (*wrapper, bool) {
+	if rt.Kind() != reflect.Struct {
+		return nil, false
+	}
+	rule, ok := m.maskWrappers[genericTypeName(rt)]
+	if !ok {
+		return nil, false
+	}
+
+	// a different type with the same name, e.g. in another function, may not have the fields
+	value, ok := rt.FieldByName(rule.valueField)
+	if !ok || !value.IsExported() || len(value.Index) != 1 {
+		return nil, false
+	}
+	w := &wrapper{value: value.Index[0], valid: -1}
+	if rule.validField != "" {
+		valid, ok := rt.FieldByName(rule.validField)
+		if !ok || !valid.IsExported() || len(valid.Index) != 1 || valid.Type.Kind() != reflect.Bool {
+			return nil, false
+		}
+		w.valid = valid.Index[0]
+	}
+
+	return w, true
+}
+
+// wrapperFor returns the wrapper to apply the tag to the value wrapped by a value of the type,
+// or nil if the tag is applied to the value itself.
+// The functions registered for the wrapper type take precedence over unwrapping.
+func (m *Masker) wrapperFor(rt reflect.Type, tag string) *wrapper {
+	if tag == "" || rt.Kind() != reflect.Struct {
+		return nil
+	}
+	if r, ok := m.maskTypeFuncs[rt]; ok && r.has(tag) {
+		return nil
+	}
+	return m.structPlan(rt).wrapper
+}
+
+// maskWrapper applies the tag to the value wrapped by rv.
+func (m *Masker) maskWrapper(w *wrapper, rv reflect.Value, tag string, mp reflect.Value, cb circuitBreaker) (reflect.Value, error) {
+	if !mp.IsValid() {
+		mp = reflect.New(rv.Type()).Elem()
+	}
+	mp.Set(rv)
+	if w.valid >= 0 && !rv.Field(w.valid).Bool() {
+		// an invalid wrapper should hold the zero value, and any other value is dropped so that it does not leak
+		mp.Field(w.value).Set(reflect.Zero(rv.Field(w.value).Type()))
+		return mp, nil
+	}
+
+	rvf, err := m.mask(rv.Field(w.value), tag, mp.Field(w.value), cb)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	mp.Field(w.value).Set(rvf)
+
+	return mp, nil
+}
+
+// genericTypeName returns the name of the type with its package path,
+// without the type arguments of a generic type.
+func genericTypeName(rt reflect.Type) string {
+	name := rt.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	return rt.PkgPath() + "." + name
+}
@@ -0,0 +1,45 @@
+//go:build go1.22
+
+package mask
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskWrapper_SQLNull(t *testing.T) {
+	type structTest struct {
+		String  sql.Null[string] `mask:"filled"`
+		Int     sql.Null[int]    `mask:"zero"`
+		Invalid sql.Null[string] `mask:"fixed"`
+	}
+	input := structTest{
+		String:  sql.Null[string]{V: "Usagi", Valid: true},
+		Int:     sql.Null[int]{V: 3, Valid: true},
+		Invalid: sql.Null[string]{V: "Chiikawa"},
+	}
+	want := structTest{
+		String:  sql.Null[string]{V: "*****", Valid: true},
+		Int:     sql.Null[int]{V: 0, Valid: true},
+		Invalid: sql.Null[string]{},
+	}
+
+	t.Run(defaultTestCase("sql.Null"), func(t *testing.T) {
+		defer cleanup(t)
+		got, err := Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("sql.Null"), func(t *testing.T) {
+		got, err := newMasker().Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package mask
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// optional is a user-defined generic wrapper type.
+type optional[T any] struct {
+	Value   T
+	Present bool
+}
+
+func TestMaskWrapper_SQL(t *testing.T) {
+	type structTest struct {
+		String     sql.NullString `mask:"filled"`
+		Invalid    sql.NullString `mask:"fixed"`
+		NoTag      sql.NullString
+		Int64      sql.NullInt64    `mask:"zero"`
+		Int32      sql.NullInt32    `mask:"pseudo1000"`
+		Float64    sql.NullFloat64  `mask:"zero"`
+		Time       sql.NullTime     `mask:"zero"`
+		Ptr        *sql.NullString  `mask:"filled"`
+		Slice      []sql.NullString `mask:"filled"`
+		ZeroStruct sql.NullString   `mask:"zero"`
+	}
+	date := time.Date(2019, 1, 22, 0, 0, 0, 0, time.UTC)
+	input := structTest{
+		String:     sql.NullString{String: "Usagi", Valid: true},
+		Invalid:    sql.NullString{String: "secret", Valid: false},
+		NoTag:      sql.NullString{String: "Chiikawa", Valid: true},
+		Int64:      sql.NullInt64{Int64: 100, Valid: true},
+		Int32:      sql.NullInt32{Int32: 100, Valid: true},
+		Float64:    sql.NullFloat64{Float64: 1.5, Valid: true},
+		Time:       sql.NullTime{Time: date, Valid: true},
+		Ptr:        &sql.NullString{String: "Hachiware", Valid: true},
+		Slice:      []sql.NullString{{String: "Momonga", Valid: true}, {}},
+		ZeroStruct: sql.NullString{String: "Kurimanju", Valid: true},
+	}
+
+	m := newMasker()
+	m.SetPseudoKey([]byte("secret"))
+	pseudo, err := m.MaskPseudoInt("1000", 100)
+	assert.NoError(t, err)
+	want := structTest{
+		String:     sql.NullString{String: "*****", Valid: true},
+		Invalid:    sql.NullString{String: "", Valid: false},
+		NoTag:      sql.NullString{String: "Chiikawa", Valid: true},
+		Int64:      sql.NullInt64{Int64: 0, Valid: true},
+		Int32:      sql.NullInt32{Int32: int32(pseudo), Valid: true},
+		Float64:    sql.NullFloat64{Float64: 0, Valid: true},
+		Time:       sql.NullTime{Valid: true},
+		Ptr:        &sql.NullString{String: "*********", Valid: true},
+		Slice:      []sql.NullString{{String: "*******", Valid: true}, {}},
+		ZeroStruct: sql.NullString{String: "", Valid: true},
+	}
+
+	for _, cache := range []bool{true, false} {
+		m.Cache(cache)
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	}
+	assert.NoError(t, m.Validate(structTest{}))
+
+	t.Run(newMaskerTestCase("unmatched"), func(t *testing.T) {
+		m := newMasker()
+		m.SetUnmatchedPolicy(UnmatchedFail)
+		_, err := m.Mask(struct {
+			Bool sql.NullBool `mask:"filled"`
+		}{Bool: sql.NullBool{Bool: true, Valid: true}})
+		assert.ErrorIs(t, err, ErrIncompatibleMaskType)
+
+		err = m.Validate(struct {
+			Bool sql.NullBool `mask:"filled"`
+		}{})
+		assert.ErrorIs(t, err, ErrIncompatibleMaskType)
+	})
+}
+
+func TestRegisterMaskWrapper(t *testing.T) {
+	type structTest struct {
+		Name    optional[string]   `mask:"filled"`
+		Age     optional[int]      `mask:"zero"`
+		Absent  optional[string]   `mask:"fixed"`
+		Names   []optional[string] `mask:"filled"`
+		Untyped optional[float64]
+	}
+	input := structTest{
+		Name:    optional[string]{Value: "Usagi", Present: true},
+		Age:     optional[int]{Value: 3, Present: true},
+		Absent:  optional[string]{Value: "Chiikawa"},
+		Names:   []optional[string]{{Value: "Hachiware", Present: true}},
+		Untyped: optional[float64]{Value: 1.5, Present: true},
+	}
+	want := structTest{
+		Name:    optional[string]{Value: "*****", Present: true},
+		Age:     optional[int]{Value: 0, Present: true},
+		Absent:  optional[string]{},
+		Names:   []optional[string]{{Value: "*********", Present: true}},
+		Untyped: optional[float64]{Value: 1.5, Present: true},
+	}
+
+	t.Run(defaultTestCase("generic wrapper"), func(t *testing.T) {
+		defer cleanup(t)
+		RegisterMaskWrapper(reflect.TypeOf(optional[string]{}), "Value", "Present")
+		got, err := Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("generic wrapper"), func(t *testing.T) {
+		m := newMasker()
+		m.RegisterMaskWrapper(reflect.TypeOf(optional[string]{}), "Value", "Present")
+		got, err := m.Mask(input)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run(newMaskerTestCase("without valid field"), func(t *testing.T) {
+		type secret struct {
+			Value string
+			Note  string
+		}
+		m := newMasker()
+		m.RegisterMaskWrapper(reflect.TypeOf(secret{}), "Value", "")
+		got, err := m.Mask(struct {
+			Secret secret `mask:"filled"`
+		}{Secret: secret{Value: "Usagi", Note: "Chiikawa"}})
+		assert.NoError(t, err)
+		assert.Equal(t, struct {
+			Secret secret `mask:"filled"`
+		}{Secret: secret{Value: "*****", Note: "Chiikawa"}}, got)
+	})
+	t.Run(newMaskerTestCase("missing field"), func(t *testing.T) {
+		m := newMasker()
+		m.SetUnmatchedPolicy(UnmatchedFail)
+		m.RegisterMaskWrapper(reflect.TypeOf(optional[string]{}), "Val", "Present")
+		_, err := m.Mask(input)
+		assert.ErrorIs(t, err, ErrIncompatibleMaskType)
+	})
+}